@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/service"
 
 	"github.com/zeromicro/grpc-mock/config"
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
 	"github.com/zeromicro/grpc-mock/internal/controlapi"
 	"github.com/zeromicro/grpc-mock/internal/proxy"
 	"github.com/zeromicro/grpc-mock/internal/svc"
@@ -24,6 +27,16 @@ func main() {
 
 	svcCtx := svc.NewServiceContext(c)
 
+	ctx := context.Background()
+	svcCtx.DialManager.AddUpstreamWithRetry(ctx, c.Upstreams)
+
+	cases, err := casemanager.LoadFiles(c.CaseFiles)
+	logx.Must(err)
+	svcCtx.CaseManager.Declare(ctx, append(c.Cases, cases...))
+
+	if c.CaseWatch.Path != "" {
+		sg.Add(casemanager.NewWatcher(svcCtx.CaseManager, c.CaseWatch))
+	}
 	sg.Add(controlapi.NewControl(svcCtx))
 	sg.Add(proxy.NewProxy(svcCtx))
 
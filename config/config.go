@@ -1,9 +1,15 @@
 package config
 
 import (
+	caseConfig "github.com/zeromicro/grpc-mock/internal/casemanager/config"
 	controlConfig "github.com/zeromicro/grpc-mock/internal/controlapi/config"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	journalConfig "github.com/zeromicro/grpc-mock/internal/journal/config"
 	"github.com/zeromicro/grpc-mock/internal/match/config"
 	proxyConfig "github.com/zeromicro/grpc-mock/internal/proxy/config"
+	recordConfig "github.com/zeromicro/grpc-mock/internal/record/config"
+	storageConfig "github.com/zeromicro/grpc-mock/internal/storage/config"
 )
 
 type (
@@ -11,5 +17,12 @@ type (
 		ControlService controlConfig.Config
 		ProxyService   proxyConfig.Config
 		MatchConf      config.MatchConfig
+		Storage        storageConfig.StorageConfig
+		Upstreams      []dialmanager.RpcClientConf `json:",optional"`
+		Cases          []types.Case                `json:",optional"`
+		CaseFiles      []string                    `json:",optional"` // glob patterns of case definition files
+		CaseWatch      caseConfig.WatchConfig
+		Record         recordConfig.RecordConfig
+		Journal        journalConfig.JournalConfig
 	}
 )
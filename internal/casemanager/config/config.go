@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+type WatchConfig struct {
+	Path     string        `json:",optional"` // directory of case definition files, empty disables watching
+	Interval time.Duration `json:",default=5s"`
+}
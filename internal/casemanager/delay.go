@@ -0,0 +1,70 @@
+package casemanager
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+)
+
+// Delay draws the latency to inject before replying, all the values of the spec are in
+// milliseconds and the parts add up:
+//   - Fixed is always applied.
+//   - Min and Max add a uniformly distributed delay when Max is set.
+//   - Percentiles add a delay following the distribution described by the points, e.g.
+//     p50=20 and p99=300 means half of the calls take less than 20ms and 1% more than 300ms,
+//     the delay is interpolated linearly between the points.
+func Delay(d types.CaseDelay) time.Duration {
+	delay := float64(d.Fixed)
+
+	if d.Max > 0 {
+		delay += float64(d.Min) + rand.Float64()*float64(d.Max-d.Min)
+	}
+
+	if len(d.Percentiles) > 0 {
+		delay += percentileDelay(d.Percentiles, rand.Float64()*100)
+	}
+
+	return time.Duration(delay * float64(time.Millisecond))
+}
+
+// ValidateDelay checks the delay spec, percentiles must be ascending on both axes.
+func ValidateDelay(d types.CaseDelay) error {
+	if d.Fixed < 0 || d.Min < 0 || d.Max < 0 {
+		return errors.New("delays must not be negative")
+	}
+	if d.Min > 0 && d.Max == 0 {
+		return fmt.Errorf("min %d is set without max, use fixed for a constant delay", d.Min)
+	}
+	if d.Max > 0 && d.Min > d.Max {
+		return fmt.Errorf("min %d is greater than max %d", d.Min, d.Max)
+	}
+
+	var last types.DelayPercentile
+	for i, p := range d.Percentiles {
+		if p.Percentile <= last.Percentile || p.Percentile > 100 {
+			return fmt.Errorf("percentiles[%d]: percentile must be ascending within (0, 100]", i)
+		}
+		if p.Delay < last.Delay {
+			return fmt.Errorf("percentiles[%d]: delay must be ascending", i)
+		}
+		last = p
+	}
+
+	return nil
+}
+
+func percentileDelay(points []types.DelayPercentile, percentile float64) float64 {
+	var last types.DelayPercentile
+	for _, p := range points {
+		if percentile <= p.Percentile {
+			ratio := (percentile - last.Percentile) / (p.Percentile - last.Percentile)
+			return float64(last.Delay) + ratio*float64(p.Delay-last.Delay)
+		}
+		last = p
+	}
+
+	return float64(last.Delay)
+}
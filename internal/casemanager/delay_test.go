@@ -0,0 +1,107 @@
+package casemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+)
+
+func TestValidateDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   types.CaseDelay
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "fixed", delay: types.CaseDelay{Fixed: 100}},
+		{name: "uniform", delay: types.CaseDelay{Min: 10, Max: 20}},
+		{name: "max only", delay: types.CaseDelay{Max: 20}},
+		{name: "min equals max", delay: types.CaseDelay{Min: 20, Max: 20}},
+		{name: "min without max", delay: types.CaseDelay{Min: 500}, wantErr: true},
+		{name: "min above max", delay: types.CaseDelay{Min: 30, Max: 20}, wantErr: true},
+		{name: "negative fixed", delay: types.CaseDelay{Fixed: -1}, wantErr: true},
+		{name: "negative max", delay: types.CaseDelay{Max: -1}, wantErr: true},
+		{
+			name: "percentiles",
+			delay: types.CaseDelay{Percentiles: []types.DelayPercentile{
+				{Percentile: 50, Delay: 20}, {Percentile: 99, Delay: 300}, {Percentile: 100, Delay: 300},
+			}},
+		},
+		{
+			name: "percentiles not ascending",
+			delay: types.CaseDelay{Percentiles: []types.DelayPercentile{
+				{Percentile: 99, Delay: 300}, {Percentile: 50, Delay: 400},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "percentile above 100",
+			delay:   types.CaseDelay{Percentiles: []types.DelayPercentile{{Percentile: 101, Delay: 300}}},
+			wantErr: true,
+		},
+		{
+			name: "percentile delays not ascending",
+			delay: types.CaseDelay{Percentiles: []types.DelayPercentile{
+				{Percentile: 50, Delay: 300}, {Percentile: 99, Delay: 20},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateDelay(test.delay)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Delay(types.CaseDelay{}))
+	assert.Equal(t, 100*time.Millisecond, Delay(types.CaseDelay{Fixed: 100}))
+
+	for i := 0; i < 1000; i++ {
+		delay := Delay(types.CaseDelay{Fixed: 5, Min: 10, Max: 20})
+		assert.GreaterOrEqual(t, delay, 15*time.Millisecond)
+		assert.LessOrEqual(t, delay, 25*time.Millisecond)
+	}
+}
+
+func TestDelay_Percentiles(t *testing.T) {
+	d := types.CaseDelay{Percentiles: []types.DelayPercentile{
+		{Percentile: 50, Delay: 20}, {Percentile: 90, Delay: 100}, {Percentile: 100, Delay: 300},
+	}}
+
+	const n = 10000
+	var below20, below100 int
+	for i := 0; i < n; i++ {
+		delay := Delay(d)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+		if delay <= 20*time.Millisecond {
+			below20++
+		}
+		if delay <= 100*time.Millisecond {
+			below100++
+		}
+	}
+
+	assert.InDelta(t, 0.5, float64(below20)/n, 0.03)
+	assert.InDelta(t, 0.9, float64(below100)/n, 0.03)
+}
+
+func TestPercentileDelay(t *testing.T) {
+	points := []types.DelayPercentile{{Percentile: 50, Delay: 20}, {Percentile: 100, Delay: 120}}
+
+	assert.Equal(t, 0.0, percentileDelay(points, 0))
+	assert.Equal(t, 10.0, percentileDelay(points, 25))
+	assert.Equal(t, 20.0, percentileDelay(points, 50))
+	assert.Equal(t, 70.0, percentileDelay(points, 75))
+	assert.Equal(t, 120.0, percentileDelay(points, 100))
+}
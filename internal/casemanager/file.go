@@ -0,0 +1,46 @@
+package casemanager
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/zeromicro/go-zero/core/conf"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+)
+
+// caseFile is the layout of a case definition file, json, yaml and toml are supported.
+type caseFile struct {
+	Cases []types.Case `json:"cases"`
+}
+
+// LoadFiles loads the cases declared in the files matched by the glob patterns.
+func LoadFiles(patterns []string) ([]types.Case, error) {
+	var cases []types.Case
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			fileCases, err := LoadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, fileCases...)
+		}
+	}
+
+	return cases, nil
+}
+
+// LoadFile loads the cases declared in the given file.
+func LoadFile(file string) ([]types.Case, error) {
+	var c caseFile
+	if err := conf.Load(file, &c); err != nil {
+		return nil, fmt.Errorf("load case file %s: %w", file, err)
+	}
+
+	return c.Cases, nil
+}
@@ -2,77 +2,213 @@ package casemanager
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/storage"
 )
 
 type Manager struct {
-	mutex sync.RWMutex
-	cases map[string]map[string]types.Case // methodName -> caseName -> case
+	mutex    sync.RWMutex
+	cases    map[string]map[string]types.Case // methodName -> caseName -> case
+	watched  map[string]map[string]types.Case // methodName -> caseName -> case, loaded from the watched directory
+	files    []FileStatus
+	store    storage.CaseStorage
+	resolver MethodResolver
+	rules    map[string]compiledRule // rule source -> compiled rule, for the rules of the cases above
+	stamped  int64                   // last insertion time given to a case, in unix nanoseconds
 }
 
-func NewManager() *Manager {
+func NewManager(store storage.CaseStorage, resolver MethodResolver) *Manager {
 	return &Manager{
-		cases: make(map[string]map[string]types.Case),
+		cases:    make(map[string]map[string]types.Case),
+		watched:  make(map[string]map[string]types.Case),
+		rules:    make(map[string]compiledRule),
+		store:    store,
+		resolver: resolver,
 	}
 }
 
+// Load replaces the in-memory cases with the ones kept in the storage.
+func (m *Manager) Load(ctx context.Context) error {
+	cases, err := m.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cases = make(map[string]map[string]types.Case)
+	for _, _case := range cases {
+		if _case.CreatedAt == 0 {
+			// stored before cases had an insertion time
+			_case.CreatedAt = m.stamp()
+		} else if _case.CreatedAt > m.stamped {
+			m.stamped = _case.CreatedAt
+		}
+		m.compile(_case)
+		put(m.cases, _case)
+	}
+	m.prune()
+
+	return nil
+}
+
+// Declare registers the cases that come from the config, they are kept in memory only
+// because the config stays their source of truth.
+func (m *Manager) Declare(ctx context.Context, cases []types.Case) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, _case := range cases {
+		_case.CreatedAt = m.insertedAt(m.cases, _case)
+		m.compile(_case)
+		put(m.cases, _case)
+	}
+	m.prune()
+}
+
 func (m *Manager) CaseAdd(ctx context.Context, _case types.Case) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, ok := m.cases[_case.MethodName]; !ok {
-		m.cases[_case.MethodName] = make(map[string]types.Case)
+	_case.CreatedAt = m.insertedAt(m.cases, _case)
+	if err := m.store.Save(ctx, _case); err != nil {
+		return err
 	}
 
-	m.cases[_case.MethodName][_case.Name] = _case
+	m.compile(_case)
+	put(m.cases, _case)
+	m.prune()
 	return nil
 }
 
+// CaseDel deletes the case set through the api, the cases loaded from the watched directory can
+// only be deleted from their file.
 func (m *Manager) CaseDel(ctx context.Context, methodName, name string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, ok := m.cases[methodName]; !ok {
+	if _, ok := m.cases[methodName][name]; !ok {
+		if _, ok = m.watched[methodName][name]; ok {
+			return fmt.Errorf("case %s of %s is owned by a case file of the watched directory, remove it from the file",
+				name, methodName)
+		}
 		return nil
 	}
 
-	if _, ok := m.cases[methodName][name]; !ok {
-		return nil
+	if err := m.store.Delete(ctx, methodName, name); err != nil {
+		return err
 	}
 
 	delete(m.cases[methodName], name)
+	m.prune()
 	return nil
 }
 
+// CaseGet returns the named case, the cases set through the api take precedence over
+// the ones loaded from the watched directory.
 func (m *Manager) CaseGet(ctx context.Context, methodName, name string) (types.Case, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	if _, ok := m.cases[methodName]; !ok {
-		return types.Case{}, nil
+	if _case, ok := m.cases[methodName][name]; ok {
+		return _case, nil
 	}
 
-	if _, ok := m.cases[methodName][name]; !ok {
-		return types.Case{}, nil
+	if _case, ok := m.watched[methodName][name]; ok {
+		return _case, nil
 	}
 
-	return m.cases[methodName][name], nil
+	return types.Case{}, nil
 }
 
+// CaseList returns the cases of the method in the order they are evaluated: higher priority
+// first, then the earlier inserted.
 func (m *Manager) CaseList(ctx context.Context, methodName string) ([]types.Case, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	if _, ok := m.cases[methodName]; !ok {
-		return nil, nil
-	}
-
 	var cases []types.Case
 	for _, _case := range m.cases[methodName] {
 		cases = append(cases, _case)
 	}
 
+	for name, _case := range m.watched[methodName] {
+		if _, ok := m.cases[methodName][name]; ok {
+			continue
+		}
+		cases = append(cases, _case)
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].Priority != cases[j].Priority {
+			return cases[i].Priority > cases[j].Priority
+		}
+		if cases[i].CreatedAt != cases[j].CreatedAt {
+			return cases[i].CreatedAt < cases[j].CreatedAt
+		}
+		return cases[i].Name < cases[j].Name
+	})
+
 	return cases, nil
 }
+
+// CaseFiles returns the load status of the files in the watched directory.
+func (m *Manager) CaseFiles(ctx context.Context) []FileStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]FileStatus(nil), m.files...)
+}
+
+// swapWatched atomically replaces the cases loaded from the watched directory.
+func (m *Manager) swapWatched(cases []types.Case, files []FileStatus) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	watched := make(map[string]map[string]types.Case)
+	for _, _case := range cases {
+		_case.CreatedAt = m.insertedAt(m.watched, _case)
+		m.compile(_case)
+		put(watched, _case)
+	}
+
+	m.watched = watched
+	m.files = files
+	m.prune()
+}
+
+// insertedAt returns the insertion time of the case: a case replacing one of the same name keeps
+// its place, a new one goes after all the others. The caller must hold the lock.
+func (m *Manager) insertedAt(cases map[string]map[string]types.Case, _case types.Case) int64 {
+	if old, ok := cases[_case.MethodName][_case.Name]; ok && old.CreatedAt != 0 {
+		return old.CreatedAt
+	}
+
+	return m.stamp()
+}
+
+// stamp returns the current time, later than any returned before so that cases inserted at once
+// keep their order. The caller must hold the lock.
+func (m *Manager) stamp() int64 {
+	now := time.Now().UnixNano()
+	if now <= m.stamped {
+		now = m.stamped + 1
+	}
+	m.stamped = now
+
+	return now
+}
+
+func put(cases map[string]map[string]types.Case, _case types.Case) {
+	if _, ok := cases[_case.MethodName]; !ok {
+		cases[_case.MethodName] = make(map[string]types.Case)
+	}
+
+	cases[_case.MethodName][_case.Name] = _case
+}
@@ -0,0 +1,43 @@
+package casemanager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const binHeaderSuffix = "-bin"
+
+// Metadata converts the case headers or trailers into gRPC metadata, the values of binary
+// keys (ending with -bin) are base64 encoded in the case and decoded here.
+func Metadata(kvs map[string]string) (metadata.MD, error) {
+	md := metadata.MD{}
+	for key, value := range kvs {
+		key = strings.ToLower(key)
+		if key == "" || strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") {
+			return nil, fmt.Errorf("invalid metadata key %q", key)
+		}
+
+		if strings.HasSuffix(key, binHeaderSuffix) {
+			decoded, err := decodeBinHeader(value)
+			if err != nil {
+				return nil, fmt.Errorf("metadata %s: %w", key, err)
+			}
+			value = string(decoded)
+		}
+
+		md.Append(key, value)
+	}
+
+	return md, nil
+}
+
+// decodeBinHeader accepts both padded and unpadded base64, like gRPC does on the wire.
+func decodeBinHeader(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+	return base64.RawStdEncoding.DecodeString(v)
+}
@@ -0,0 +1,76 @@
+package casemanager
+
+import (
+	"github.com/antonmedv/expr/vm"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+// compiledRule is a rule compiled once, err is kept so that an invalid rule is not compiled again
+// on every request.
+type compiledRule struct {
+	program *vm.Program
+	err     error
+}
+
+// Program returns the compiled rule. Rules are compiled when their case is set and dropped when no
+// case uses them anymore, a rule no case uses is compiled on every call.
+func (m *Manager) Program(source string) (*vm.Program, error) {
+	m.mutex.RLock()
+	compiled, ok := m.rules[source]
+	m.mutex.RUnlock()
+	if ok {
+		return compiled.program, compiled.err
+	}
+
+	return rule.Compile(source)
+}
+
+// compile compiles the rules of the case that are not compiled yet, the caller must hold the lock.
+func (m *Manager) compile(_case types.Case) {
+	sources := caseRules(_case)
+	for _, source := range sources {
+		if _, ok := m.rules[source]; ok {
+			continue
+		}
+
+		program, err := rule.Compile(source)
+		m.rules[source] = compiledRule{program: program, err: err}
+	}
+}
+
+// prune drops the compiled rules no case uses anymore, the caller must hold the lock.
+func (m *Manager) prune() {
+	used := make(map[string]struct{}, len(m.rules))
+	for _, cases := range []map[string]map[string]types.Case{m.cases, m.watched} {
+		for _, methodCases := range cases {
+			for _, _case := range methodCases {
+				for _, source := range caseRules(_case) {
+					used[source] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for source := range m.rules {
+		if _, ok := used[source]; !ok {
+			delete(m.rules, source)
+		}
+	}
+}
+
+// caseRules returns the rule of the case and the ones of its script steps.
+func caseRules(_case types.Case) []string {
+	var sources []string
+	if _case.Rule != "" {
+		sources = append(sources, _case.Rule)
+	}
+	for _, step := range _case.Script {
+		if step.Rule != "" {
+			sources = append(sources, step.Rule)
+		}
+	}
+
+	return sources
+}
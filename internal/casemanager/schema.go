@@ -0,0 +1,257 @@
+package casemanager
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// checkJSON reports every unknown field and value of the wrong type in the json form of a message
+// of type md, each with its path, e.g. body.items[0].name. jsonpb stops at the first problem and
+// does not tell where it is. Well-known types, which have their own json form, are not checked.
+func checkJSON(md *desc.MessageDescriptor, v interface{}, path string) []FieldError {
+	if isWellKnown(md) || v == nil {
+		return nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return []FieldError{{Field: path, Message: fmt.Sprintf("expected an object of %s", md.GetFullyQualifiedName())}}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []FieldError
+	for _, key := range keys {
+		fd := findField(md, key)
+		if fd == nil {
+			if strings.HasPrefix(key, "[") {
+				continue // extension
+			}
+			errs = append(errs, FieldError{
+				Field:   path + "." + key,
+				Message: fmt.Sprintf("unknown field of %s", md.GetFullyQualifiedName()),
+			})
+			continue
+		}
+
+		errs = append(errs, checkField(fd, obj[key], path+"."+key)...)
+	}
+
+	return errs
+}
+
+// findField looks the field up by its proto name, then by its json name, like jsonpb does.
+func findField(md *desc.MessageDescriptor, name string) *desc.FieldDescriptor {
+	if fd := md.FindFieldByName(name); fd != nil {
+		return fd
+	}
+	for _, fd := range md.GetFields() {
+		if fd.GetJSONName() == name {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+func checkField(fd *desc.FieldDescriptor, v interface{}, path string) []FieldError {
+	if v == nil {
+		return nil
+	}
+
+	switch {
+	case fd.IsMap():
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return []FieldError{{Field: path, Message: "expected an object for a map field"}}
+		}
+
+		var errs []FieldError
+		for key, value := range obj {
+			keyPath := fmt.Sprintf("%s[%q]", path, key)
+			if err := checkScalar(fd.GetMapKeyType(), key); err != "" {
+				errs = append(errs, FieldError{Field: keyPath, Message: "key " + err})
+			}
+			errs = append(errs, checkValue(fd.GetMapValueType(), value, keyPath)...)
+		}
+		return errs
+	case fd.IsRepeated():
+		arr, ok := v.([]interface{})
+		if !ok {
+			return []FieldError{{Field: path, Message: "expected an array for a repeated field"}}
+		}
+
+		var errs []FieldError
+		for i, value := range arr {
+			errs = append(errs, checkValue(fd, value, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	default:
+		return checkValue(fd, v, path)
+	}
+}
+
+// checkValue checks a single value of the field, an element for repeated fields.
+func checkValue(fd *desc.FieldDescriptor, v interface{}, path string) []FieldError {
+	if v == nil {
+		return nil
+	}
+	if md := fd.GetMessageType(); md != nil {
+		return checkJSON(md, v, path)
+	}
+	if err := checkScalar(fd, v); err != "" {
+		return []FieldError{{Field: path, Message: err}}
+	}
+
+	return nil
+}
+
+// checkScalar returns what is wrong with the scalar or enum value, empty if nothing. Numbers may
+// be quoted and map keys are always strings, as in the json mapping of proto3.
+func checkScalar(fd *desc.FieldDescriptor, v interface{}) string {
+	str, isStr := v.(string)
+	num, isNum := v.(json.Number)
+	if isNum {
+		str = num.String()
+	}
+
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		if !isStr {
+			return "expected a string"
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		if !isStr {
+			return "expected a base64 string"
+		}
+		if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+			if _, err = base64.URLEncoding.DecodeString(str); err != nil {
+				return "expected a base64 string"
+			}
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if _, ok := v.(bool); !ok && !(isStr && (str == "true" || str == "false")) {
+			return "expected a bool"
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		ed := fd.GetEnumType()
+		if isNum {
+			if _, err := strconv.ParseInt(str, 10, 32); err != nil {
+				return fmt.Sprintf("expected a value of %s", ed.GetFullyQualifiedName())
+			}
+			return ""
+		}
+		if !isStr || ed.FindValueByName(str) == nil {
+			return fmt.Sprintf("expected a value of %s", ed.GetFullyQualifiedName())
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		if !isNum && !isStr {
+			return "expected a number"
+		}
+		if str == "NaN" || str == "Infinity" || str == "-Infinity" {
+			return ""
+		}
+		if _, err := strconv.ParseFloat(str, 64); err != nil {
+			return "expected a number"
+		}
+	default:
+		if !isNum && !isStr {
+			return "expected an integer"
+		}
+		return checkInteger(fd.GetType(), str)
+	}
+
+	return ""
+}
+
+func checkInteger(typ descriptorpb.FieldDescriptorProto_Type, str string) string {
+	var err error
+	switch typ {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		_, err = strconv.ParseInt(str, 10, 32)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		_, err = strconv.ParseUint(str, 10, 32)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		_, err = strconv.ParseUint(str, 10, 64)
+	default:
+		_, err = strconv.ParseInt(str, 10, 64)
+	}
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return "integer out of range"
+		}
+		return "expected an integer"
+	}
+
+	return ""
+}
+
+func isWellKnown(md *desc.MessageDescriptor) bool {
+	return strings.HasPrefix(md.GetFullyQualifiedName(), "google.protobuf.")
+}
+
+// checkRulePath reports the first segment of a gjson path that does not exist in the json form of
+// the request message md, which uses the proto field names. Paths using gjson modifiers,
+// wildcards or queries are not checked.
+func checkRulePath(md *desc.MessageDescriptor, path string) string {
+	if path == "" || strings.ContainsAny(path, `\*?|@[]{}()!=<>%"`) {
+		return ""
+	}
+
+	var (
+		element  *desc.FieldDescriptor // repeated or map field whose index or key comes next
+		previous string
+	)
+	for _, seg := range strings.Split(path, ".") {
+		if element != nil {
+			if element.IsMap() {
+				md = element.GetMapValueType().GetMessageType()
+			} else if _, err := strconv.Atoi(seg); err == nil || seg == "#" {
+				md = element.GetMessageType()
+			} else {
+				return fmt.Sprintf("%s is repeated, expected an index or # before %s", previous, seg)
+			}
+			element = nil
+			previous = seg
+			continue
+		}
+
+		if md == nil {
+			return fmt.Sprintf("%s is not a message, it has no field %s", previous, seg)
+		}
+		if isWellKnown(md) {
+			return ""
+		}
+
+		fd := md.FindFieldByName(seg)
+		if fd == nil {
+			for _, f := range md.GetFields() {
+				if f.GetJSONName() == seg {
+					return fmt.Sprintf("rules see proto field names, use %s instead of %s", f.GetName(), seg)
+				}
+			}
+			return fmt.Sprintf("unknown field %s of %s", seg, md.GetFullyQualifiedName())
+		}
+
+		if fd.IsRepeated() {
+			element = fd
+		} else {
+			md = fd.GetMessageType()
+		}
+		previous = seg
+	}
+
+	return ""
+}
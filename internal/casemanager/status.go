@@ -0,0 +1,40 @@
+package casemanager
+
+import (
+	"fmt"
+
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails" // registers the google.rpc error details types
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+)
+
+// Status converts the case status into a gRPC status, nil means the case replies with its body.
+// Each detail is a google.protobuf.Any in its json form, e.g.
+// {"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "5s"}.
+func Status(s types.CaseStatus) (*status.Status, error) {
+	if s.Code == int(codes.OK) {
+		return nil, nil
+	}
+	if s.Code < 0 || s.Code > int(codes.Unauthenticated) {
+		return nil, fmt.Errorf("invalid status code %d", s.Code)
+	}
+
+	st := &spb.Status{
+		Code:    int32(s.Code),
+		Message: s.Message,
+	}
+	for i, detail := range s.Details {
+		var any anypb.Any
+		if err := protojson.Unmarshal([]byte(detail), &any); err != nil {
+			return nil, fmt.Errorf("details[%d]: %w", i, err)
+		}
+		st.Details = append(st.Details, &any)
+	}
+
+	return status.FromProto(st), nil
+}
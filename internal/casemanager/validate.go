@@ -0,0 +1,169 @@
+package casemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/render"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+type (
+	// MethodResolver looks up the descriptor of a method, it is implemented by dialmanager.Manager.
+	MethodResolver interface {
+		MethodDetail(ctx context.Context, method string) (parser.MethodDesc, error)
+	}
+
+	// FieldError is a problem with one field of a case, Field is its json path, e.g. stream[1].body.id.
+	FieldError struct {
+		Field   string
+		Message string
+	}
+
+	// ValidationError lists every problem found in a case.
+	ValidationError []FieldError
+)
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the case against the schema of its method, the returned error is a
+// ValidationError listing every problem found.
+func (m *Manager) Validate(ctx context.Context, _case types.Case) error {
+	var errs ValidationError
+	add := func(field string, err error) {
+		if err != nil {
+			errs = append(errs, FieldError{Field: field, Message: err.Error()})
+		}
+	}
+
+	if _case.MethodName == "" {
+		errs = append(errs, FieldError{Field: "method_name", Message: "is required"})
+	}
+	if _case.Name == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "is required"})
+	}
+	if _case.MethodName == "" {
+		return errs
+	}
+
+	desc, err := m.resolver.MethodDetail(ctx, _case.MethodName)
+	if err != nil {
+		return append(errs, FieldError{Field: "method_name", Message: fmt.Sprintf("method %s: %s", _case.MethodName, err)})
+	}
+
+	if _case.Rule != "" {
+		errs = append(errs, validateRule(desc, "rule", _case.Rule)...)
+	}
+
+	_, err = Status(_case.Status)
+	add("status", err)
+	add("delay", ValidateDelay(_case.Delay))
+	_, err = Metadata(_case.Headers)
+	add("headers", err)
+	_, err = Metadata(_case.Trailers)
+	add("trailers", err)
+
+	if _case.Body != "" {
+		errs = append(errs, validateBody(desc, "body", _case.Body, _case.Template)...)
+	}
+
+	if len(_case.Stream) > 0 && !desc.ServerStreaming {
+		errs = append(errs, FieldError{Field: "stream", Message: fmt.Sprintf("method %s is not server streaming", _case.MethodName)})
+	}
+	errs = append(errs, validateMessages(desc, "stream", _case.Stream, _case.Template)...)
+
+	if len(_case.Script) > 0 && !(desc.ClientStreaming && desc.ServerStreaming) {
+		errs = append(errs, FieldError{Field: "script", Message: fmt.Sprintf("method %s is not bidi streaming", _case.MethodName)})
+	}
+	for i, step := range _case.Script {
+		if step.Rule != "" {
+			errs = append(errs, validateRule(desc, fmt.Sprintf("script[%d].rule", i), step.Rule)...)
+		}
+		errs = append(errs, validateMessages(desc, fmt.Sprintf("script[%d].reply", i), step.Reply, _case.Template)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateRule compiles the rule against the request environment and checks the fields its json
+// queries refer to exist in the request message.
+func validateRule(desc parser.MethodDesc, field, r string) []FieldError {
+	if _, err := rule.Compile(r); err != nil {
+		return []FieldError{{Field: field, Message: err.Error()}}
+	}
+
+	var errs []FieldError
+	for _, path := range rule.Paths(r) {
+		if msg := checkRulePath(desc.In.RawDesc, path); msg != "" {
+			errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("json(%q): %s", path, msg)})
+		}
+	}
+
+	return errs
+}
+
+func validateMessages(desc parser.MethodDesc, field string, messages []types.StreamMessage, template bool) []FieldError {
+	var errs []FieldError
+	for i, message := range messages {
+		if message.Delay < 0 {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("%s[%d].delay", field, i), Message: "must not be negative"})
+		}
+
+		errs = append(errs, validateBody(desc, fmt.Sprintf("%s[%d].body", field, i), message.Body, template)...)
+	}
+
+	return errs
+}
+
+// validateBody checks the body against the output message, only the template syntax of a
+// templated body can be checked since its json depends on the request.
+func validateBody(desc parser.MethodDesc, field, body string, template bool) []FieldError {
+	if template {
+		if _, err := render.Parse(body); err != nil {
+			return []FieldError{{Field: field, Message: err.Error()}}
+		}
+		return nil
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewBufferString(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return []FieldError{{Field: field, Message: "invalid json: " + err.Error()}}
+	}
+
+	if errs := checkJSON(desc.Out.RawDesc, v, field); len(errs) > 0 {
+		return errs
+	}
+
+	// the json mapping has more rules than checkJSON knows, e.g. for well-known types
+	msg := dynamic.NewMessage(desc.Out.RawDesc)
+	if err := jsonpb.UnmarshalString(body, msg); err != nil {
+		return []FieldError{{Field: field, Message: err.Error()}}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package casemanager
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager/config"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+)
+
+// defaultWatchInterval is the poll interval used when the configured one is not positive.
+const defaultWatchInterval = 5 * time.Second
+
+var caseFileExts = map[string]struct{}{
+	".json": {},
+	".yaml": {},
+	".yml":  {},
+	".toml": {},
+}
+
+// FileStatus is the result of the last load of a case definition file.
+type FileStatus struct {
+	Path     string
+	Cases    int
+	Error    string
+	LoadedAt time.Time
+}
+
+// Watcher keeps the cases of a directory loaded into the Manager, the directory is polled
+// and the whole set of cases is swapped in whenever a file is added, changed or removed.
+type Watcher struct {
+	manager  *Manager
+	path     string
+	interval time.Duration
+	done     chan struct{}
+
+	signature string
+	failed    bool
+	lastGood  map[string][]types.Case // file -> cases of its last valid version
+}
+
+func NewWatcher(manager *Manager, c config.WatchConfig) *Watcher {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	return &Watcher{
+		manager:  manager,
+		path:     c.Path,
+		interval: interval,
+		done:     make(chan struct{}),
+		lastGood: make(map[string][]types.Case),
+	}
+}
+
+func (w *Watcher) Start() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.check()
+
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) Stop() {
+	close(w.done)
+}
+
+// check reloads the directory when it changed, or when the last load had errors since
+// those may be fixed by an upstream that became available in the meantime.
+func (w *Watcher) check() {
+	files, signature, err := w.scan()
+	if err != nil {
+		logx.Errorw("scan case directory error", logx.Field("path", w.path), logx.Field("err", err.Error()))
+		return
+	}
+
+	if signature == w.signature && !w.failed {
+		return
+	}
+
+	w.signature = signature
+	w.failed = w.reload(files)
+}
+
+func (w *Watcher) scan() ([]string, string, error) {
+	var (
+		files     []string
+		signature strings.Builder
+	)
+
+	err := filepath.WalkDir(w.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := caseFileExts[strings.ToLower(filepath.Ext(path))]; !ok {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, path)
+		fmt.Fprintf(&signature, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Strings(files)
+	return files, signature.String(), nil
+}
+
+// reload loads and validates every file, a file that fails keeps the cases of its last
+// valid version so that a typo never wipes out cases. It reports whether any file failed.
+func (w *Watcher) reload(files []string) bool {
+	var (
+		ctx      = context.Background()
+		cases    []types.Case
+		statuses = make([]FileStatus, 0, len(files))
+		lastGood = make(map[string][]types.Case, len(files))
+		failed   bool
+	)
+
+	for _, file := range files {
+		status := FileStatus{
+			Path:     file,
+			LoadedAt: time.Now(),
+		}
+
+		fileCases, err := w.load(ctx, file)
+		if err != nil {
+			logx.Errorw("load case file error", logx.Field("file", file), logx.Field("err", err.Error()))
+			failed = true
+			status.Error = err.Error()
+			fileCases = w.lastGood[file]
+		}
+
+		if len(fileCases) > 0 {
+			lastGood[file] = fileCases
+		}
+		status.Cases = len(fileCases)
+		statuses = append(statuses, status)
+		cases = append(cases, fileCases...)
+	}
+
+	w.lastGood = lastGood
+	w.manager.swapWatched(cases, statuses)
+	logx.Infow("case directory reloaded", logx.Field("path", w.path),
+		logx.Field("files", len(files)), logx.Field("cases", len(cases)))
+
+	return failed
+}
+
+func (w *Watcher) load(ctx context.Context, file string) ([]types.Case, error) {
+	cases, err := LoadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, _case := range cases {
+		if err = w.manager.Validate(ctx, _case); err != nil {
+			return nil, fmt.Errorf("case %s %s: %w", _case.MethodName, _case.Name, err)
+		}
+	}
+
+	return cases, nil
+}
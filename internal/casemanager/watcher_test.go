@@ -0,0 +1,32 @@
+package casemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager/config"
+)
+
+func TestNewWatcher_Interval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     time.Duration
+	}{
+		{interval: time.Second, want: time.Second},
+		{interval: 0, want: defaultWatchInterval},
+		{interval: -time.Second, want: defaultWatchInterval},
+	}
+
+	for _, test := range tests {
+		t.Run(test.interval.String(), func(t *testing.T) {
+			w := NewWatcher(NewManager(nil, nil), config.WatchConfig{Path: t.TempDir(), Interval: test.interval})
+			assert.Equal(t, test.want, w.interval)
+
+			// stopped already, Start returns after its first check instead of panicking on the ticker
+			w.Stop()
+			w.Start()
+		})
+	}
+}
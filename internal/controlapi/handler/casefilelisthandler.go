@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/logic"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+func CaseFileListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewCaseFileListLogic(r.Context(), svcCtx)
+		resp, err := l.CaseFileList()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
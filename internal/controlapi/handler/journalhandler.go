@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/logic"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+func JournalHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.JournalRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewJournalLogic(r.Context(), svcCtx)
+		resp, err := l.Journal(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
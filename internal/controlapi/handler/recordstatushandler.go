@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/logic"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+func RecordStatusHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewRecordStatusLogic(r.Context(), svcCtx)
+		resp, err := l.RecordStatus()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
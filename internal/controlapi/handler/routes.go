@@ -27,6 +27,16 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/upstreams/del",
 				Handler: UpstreamDelHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/upstreams/protoset",
+				Handler: UpstreamProtosetHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/upstreams/diff",
+				Handler: UpstreamDiffHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/methods",
@@ -57,6 +67,46 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/cases/detail",
 				Handler: CaseDetailHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/cases/files",
+				Handler: CaseFileListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/record",
+				Handler: RecordStatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/record/start",
+				Handler: RecordStartHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/record/stop",
+				Handler: RecordStopHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/journal",
+				Handler: JournalHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/journal/reset",
+				Handler: JournalResetHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/verify",
+				Handler: VerifyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/wait",
+				Handler: WaitHandler(serverCtx),
+			},
 		},
 	)
 }
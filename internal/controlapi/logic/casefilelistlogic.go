@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type CaseFileListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCaseFileListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CaseFileListLogic {
+	return &CaseFileListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CaseFileListLogic) CaseFileList() (resp *types.CaseFileListResponse, err error) {
+	resp = &types.CaseFileListResponse{}
+	for _, file := range l.svcCtx.CaseManager.CaseFiles(l.ctx) {
+		resp.Files = append(resp.Files, types.CaseFile{
+			Path:     file.Path,
+			Cases:    file.Cases,
+			Error:    file.Error,
+			LoadedAt: file.LoadedAt.Unix(),
+		})
+	}
+
+	return resp, nil
+}
@@ -2,13 +2,19 @@ package logic
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
 	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
 	"github.com/zeromicro/grpc-mock/internal/svc"
 )
 
+// codeInvalidCase is the error code of a case set rejected because some cases are invalid.
+const codeInvalidCase = 1
+
 type CaseSetLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -23,12 +29,40 @@ func NewCaseSetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CaseSetLo
 	}
 }
 
+// CaseSet stores the cases only if all of them are valid, otherwise none is stored and the
+// response lists the problems of each invalid case.
 func (l *CaseSetLogic) CaseSet(req *types.CaseSetRequest) (resp *types.CaseSetResponse, err error) {
+	resp = &types.CaseSetResponse{}
+	for _, _case := range req.Cases {
+		err := l.svcCtx.CaseManager.Validate(l.ctx, _case)
+		if err == nil {
+			continue
+		}
+
+		var verr casemanager.ValidationError
+		if !errors.As(err, &verr) {
+			return nil, err
+		}
+		for _, ferr := range verr {
+			resp.Errors = append(resp.Errors, types.CaseError{
+				MethodName: _case.MethodName,
+				Name:       _case.Name,
+				Field:      ferr.Field,
+				Message:    ferr.Message,
+			})
+		}
+	}
+	if len(resp.Errors) > 0 {
+		resp.ErrorCode = codeInvalidCase
+		resp.ErrorMsg = fmt.Sprintf("%d invalid field(s), no case was set", len(resp.Errors))
+		return resp, nil
+	}
+
 	for _, _case := range req.Cases {
 		if err = l.svcCtx.CaseManager.CaseAdd(l.ctx, _case); err != nil {
 			return nil, err
 		}
 	}
 
-	return &types.CaseSetResponse{}, nil
+	return resp, nil
 }
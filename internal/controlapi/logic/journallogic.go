@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/journal"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type JournalLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJournalLogic(ctx context.Context, svcCtx *svc.ServiceContext) *JournalLogic {
+	return &JournalLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *JournalLogic) Journal(req *types.JournalRequest) (resp *types.JournalResponse, err error) {
+	filter := journalFilter(req.MethodName, req.CaseName, req.Since, req.Until, req.Metadata)
+	filter.Limit = req.Limit
+	entries := l.svcCtx.Journal.Entries(l.ctx, filter)
+
+	resp = &types.JournalResponse{
+		Entries: make([]types.JournalEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, journalEntry(entry))
+	}
+
+	return resp, nil
+}
+
+// journalFilter builds the filter of journal entries, times are unix milliseconds, 0 if not set.
+func journalFilter(method, caseName string, since, until int64, md map[string]string) journal.Filter {
+	filter := journal.Filter{
+		Method:   method,
+		CaseName: caseName,
+		Metadata: md,
+	}
+	if since > 0 {
+		filter.Since = time.UnixMilli(since)
+	}
+	if until > 0 {
+		filter.Until = time.UnixMilli(until)
+	}
+
+	return filter
+}
+
+func journalEntry(entry journal.Entry) types.JournalEntry {
+	return types.JournalEntry{
+		ID:         entry.ID,
+		Time:       entry.Time.UnixMilli(),
+		MethodName: entry.Method,
+		Metadata:   entry.Metadata,
+		Requests:   entry.Requests,
+		MatchType:  entry.MatchType,
+		CaseName:   entry.CaseName,
+		Upstream:   entry.Upstream,
+		Code:       int(entry.Code),
+		Message:    entry.Message,
+		Latency:    float64(entry.Latency.Microseconds()) / 1000,
+	}
+}
@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type JournalResetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJournalResetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *JournalResetLogic {
+	return &JournalResetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *JournalResetLogic) JournalReset() (resp *types.JournalResetResponse, err error) {
+	if err = l.svcCtx.Journal.Reset(l.ctx); err != nil {
+		return nil, err
+	}
+
+	return &types.JournalResetResponse{}, nil
+}
@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type RecordStartLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRecordStartLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RecordStartLogic {
+	return &RecordStartLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RecordStartLogic) RecordStart(req *types.RecordStartRequest) (resp *types.RecordStartResponse, err error) {
+	if len(req.Methods) == 0 && len(req.Upstreams) == 0 {
+		return nil, errors.New("methods or upstreams are required")
+	}
+
+	l.svcCtx.Recorder.Start(l.ctx, req.Methods, req.Upstreams)
+
+	return &types.RecordStartResponse{}, nil
+}
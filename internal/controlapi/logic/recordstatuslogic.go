@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type RecordStatusLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRecordStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RecordStatusLogic {
+	return &RecordStatusLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RecordStatusLogic) RecordStatus() (resp *types.RecordStatusResponse, err error) {
+	methods, upstreams, recorded := l.svcCtx.Recorder.Status(l.ctx)
+
+	return &types.RecordStatusResponse{
+		Methods:   methods,
+		Upstreams: upstreams,
+		Recorded:  recorded,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type RecordStopLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRecordStopLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RecordStopLogic {
+	return &RecordStopLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RecordStopLogic) RecordStop(req *types.RecordStopRequest) (resp *types.RecordStopResponse, err error) {
+	l.svcCtx.Recorder.Stop(l.ctx, req.Methods, req.Upstreams)
+
+	return &types.RecordStopResponse{}, nil
+}
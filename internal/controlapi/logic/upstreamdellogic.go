@@ -29,6 +29,10 @@ func (l *UpstreamDelLogic) UpstreamDel(req *types.UpstreamDelRequest) (resp *typ
 		if err != nil {
 			return nil, err
 		}
+
+		if err = l.svcCtx.UpstreamStorage.Delete(l.ctx, name); err != nil {
+			return nil, err
+		}
 	}
 
 	return &types.UpstreamDelResponse{}, nil
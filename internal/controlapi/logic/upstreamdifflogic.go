@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type UpstreamDiffLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpstreamDiffLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpstreamDiffLogic {
+	return &UpstreamDiffLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpstreamDiffLogic) UpstreamDiff(req *types.UpstreamDiffRequest) (resp *types.UpstreamDiffResponse, err error) {
+	resp = &types.UpstreamDiffResponse{}
+	for name, status := range l.svcCtx.DialManager.Schemas(l.ctx) {
+		if req.Upstream != "" && req.Upstream != name {
+			continue
+		}
+
+		schema := types.UpstreamSchema{
+			Upstream:       name,
+			RefreshedAt:    unix(status.RefreshedAt),
+			Error:          status.Error,
+			ChangedAt:      unix(status.ChangedAt),
+			AddedMethods:   status.Diff.AddedMethods,
+			RemovedMethods: status.Diff.RemovedMethods,
+			ChangedMethods: status.Diff.ChangedMethods,
+		}
+		for _, message := range status.Diff.ChangedMessages {
+			schema.ChangedMessages = append(schema.ChangedMessages, types.MessageDiff{
+				Message:       message.Message,
+				AddedFields:   message.AddedFields,
+				RemovedFields: message.RemovedFields,
+				ChangedFields: message.ChangedFields,
+			})
+		}
+		resp.Schemas = append(resp.Schemas, schema)
+	}
+
+	sort.Slice(resp.Schemas, func(i, j int) bool {
+		return resp.Schemas[i].Upstream < resp.Schemas[j].Upstream
+	})
+
+	return resp, nil
+}
+
+// unix returns the unix time of t, 0 if t is not set.
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -44,10 +44,17 @@ func (l *UpstreamListLogic) UpstreamList() (resp *types.UpstreamListResponse, er
 				CACertFile:         upstream.Etcd.CACertFile,
 				InsecureSkipVerify: upstream.Etcd.InsecureSkipVerify,
 			},
-			Endpoints: upstream.Endpoints,
-			Target:    upstream.Target,
-			App:       upstream.App,
-			Token:     upstream.Token,
+			Endpoints:       upstream.Endpoints,
+			Target:          upstream.Target,
+			App:             upstream.App,
+			Token:           upstream.Token,
+			Disabled:        upstream.Disabled,
+			RefreshInterval: upstream.RefreshInterval.Milliseconds(),
+			ProtosetFile:    upstream.ProtosetFile,
+			Protos:          upstream.Protos,
+			ProtoFiles:      upstream.ProtoFiles,
+			ImportPaths:     upstream.ImportPaths,
+			Reflection:      l.svcCtx.DialManager.Reflection(l.ctx, upstream.Name),
 		})
 	}
 
@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type UpstreamProtosetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUpstreamProtosetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpstreamProtosetLogic {
+	return &UpstreamProtosetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UpstreamProtoset takes the schema of the named upstream from the base64 encoded protoset instead
+// of reflection. An unknown upstream is created as a virtual one, mocked only.
+func (l *UpstreamProtosetLogic) UpstreamProtoset(req *types.UpstreamProtosetRequest) (resp *types.UpstreamProtosetResponse, err error) {
+	if req.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	protoset, err := base64.StdEncoding.DecodeString(req.Protoset)
+	if err != nil {
+		return nil, errors.New("protoset must be base64 encoded: " + err.Error())
+	}
+	if len(protoset) == 0 {
+		return nil, errors.New("protoset is required")
+	}
+
+	upstream, ok, err := knownUpstream(l.ctx, l.svcCtx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		conf.FillDefault(&upstream)
+		upstream.Name = req.Name
+	}
+	upstream.Protoset = protoset
+
+	if err = l.svcCtx.DialManager.AddUpstream(l.ctx, []dialmanager.RpcClientConf{upstream}); err != nil {
+		return nil, err
+	}
+
+	if err = l.svcCtx.UpstreamStorage.Save(l.ctx, upstream); err != nil {
+		return nil, err
+	}
+
+	return &types.UpstreamProtosetResponse{}, nil
+}
+
+// knownUpstream returns the config of the named upstream, the stored one if it is not registered
+// yet, e.g. still being retried. ok is false if the upstream is unknown.
+func knownUpstream(ctx context.Context, svcCtx *svc.ServiceContext, name string) (dialmanager.RpcClientConf, bool, error) {
+	if upstream, err := svcCtx.DialManager.Upstream(ctx, name); err == nil {
+		return upstream, true, nil
+	}
+
+	stored, err := svcCtx.UpstreamStorage.Load(ctx)
+	if err != nil {
+		return dialmanager.RpcClientConf{}, false, err
+	}
+	for _, upstream := range stored {
+		if upstream.Name == name {
+			return upstream, true, nil
+		}
+	}
+
+	return dialmanager.RpcClientConf{}, false, nil
+}
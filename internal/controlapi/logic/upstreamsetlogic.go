@@ -2,6 +2,7 @@ package logic
 
 import (
 	"context"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -45,6 +46,23 @@ func (l *UpstreamSetLogic) UpstreamSet(req *types.UpstreamSetRequest) (resp *typ
 		rpcClient.RpcClientConf.Target = upstream.Target
 		rpcClient.RpcClientConf.App = upstream.App
 		rpcClient.RpcClientConf.Token = upstream.Token
+		rpcClient.Disabled = upstream.Disabled
+		rpcClient.RefreshInterval = time.Duration(upstream.RefreshInterval) * time.Millisecond
+		rpcClient.ProtosetFile = upstream.ProtosetFile
+		rpcClient.Protos = upstream.Protos
+		rpcClient.ProtoFiles = upstream.ProtoFiles
+		rpcClient.ImportPaths = upstream.ImportPaths
+
+		if !rpcClient.HasSchema() {
+			// the protoset uploaded through /upstreams/protoset is kept, the request cannot carry it
+			previous, ok, err := knownUpstream(l.ctx, l.svcCtx, upstream.Name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rpcClient.Protoset = previous.Protoset
+			}
+		}
 
 		clients = append(clients, rpcClient)
 	}
@@ -53,5 +71,11 @@ func (l *UpstreamSetLogic) UpstreamSet(req *types.UpstreamSetRequest) (resp *typ
 		return
 	}
 
+	for _, client := range clients {
+		if err = l.svcCtx.UpstreamStorage.Save(l.ctx, client); err != nil {
+			return
+		}
+	}
+
 	return
 }
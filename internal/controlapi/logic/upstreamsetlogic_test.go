@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/storage"
+	"github.com/zeromicro/grpc-mock/internal/storage/config"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+const testProto = `syntax = "proto3";
+package test;
+
+message Req {
+  string id = 1;
+}
+
+message Resp {}
+
+service Echo {
+  rpc Unary(Req) returns (Resp);
+}
+`
+
+// testProtoset returns the base64 encoded protoset of testProto.
+func testProtoset(t *testing.T) string {
+	p := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"test.proto": testProto}),
+	}
+	fds, err := p.ParseFiles("test.proto")
+	require.NoError(t, err)
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{fds[0].AsFileDescriptorProto()},
+	})
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func testServiceContext(t *testing.T, path string) *svc.ServiceContext {
+	upstreams, err := storage.NewUpstreamStorage(config.StorageConfig{Type: config.TypeFile, Path: path})
+	require.NoError(t, err)
+
+	return &svc.ServiceContext{
+		DialManager:     dialmanager.NewManager(),
+		UpstreamStorage: upstreams,
+	}
+}
+
+// testEndpoint returns the address of a grpc server without any service.
+func testEndpoint(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestUpstreamSet_KeepsProtoset(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []string
+	}{
+		{name: "virtual"},
+		{name: "dialed", endpoints: []string{testEndpoint(t)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			path := t.TempDir()
+			svcCtx := testServiceContext(t, path)
+
+			_, err := NewUpstreamProtosetLogic(ctx, svcCtx).UpstreamProtoset(&types.UpstreamProtosetRequest{
+				Name:     "echo",
+				Protoset: testProtoset(t),
+			})
+			require.NoError(t, err)
+
+			_, err = NewUpstreamSetLogic(ctx, svcCtx).UpstreamSet(&types.UpstreamSetRequest{
+				Upstreams: []types.RpcClientConfig{{Name: "echo", Endpoints: test.endpoints}},
+			})
+			require.NoError(t, err)
+
+			methods, err := svcCtx.DialManager.Methods(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]string{"test.Echo": {"/test.Echo/Unary"}}, methods)
+
+			upstream, err := svcCtx.DialManager.Upstream(ctx, "echo")
+			require.NoError(t, err)
+			assert.Equal(t, test.endpoints, upstream.Endpoints)
+			assert.NotEmpty(t, upstream.Protoset)
+
+			// the stored config keeps it too, so that it survives restarts
+			reloaded := testServiceContext(t, path)
+			stored, err := reloaded.UpstreamStorage.Load(ctx)
+			require.NoError(t, err)
+			require.Len(t, stored, 1)
+			assert.Equal(t, upstream.Protoset, stored[0].Protoset)
+		})
+	}
+}
+
+func TestUpstreamSet_ReplacesProtoset(t *testing.T) {
+	ctx := context.Background()
+	svcCtx := testServiceContext(t, t.TempDir())
+
+	_, err := NewUpstreamProtosetLogic(ctx, svcCtx).UpstreamProtoset(&types.UpstreamProtosetRequest{
+		Name:     "echo",
+		Protoset: testProtoset(t),
+	})
+	require.NoError(t, err)
+
+	// a schema given by the request takes over the uploaded protoset
+	_, err = NewUpstreamSetLogic(ctx, svcCtx).UpstreamSet(&types.UpstreamSetRequest{
+		Upstreams: []types.RpcClientConfig{{
+			Name:   "echo",
+			Protos: map[string]string{"other.proto": strings.ReplaceAll(testProto, "Echo", "Other")},
+		}},
+	})
+	require.NoError(t, err)
+
+	methods, err := svcCtx.DialManager.Methods(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"test.Other": {"/test.Other/Unary"}}, methods)
+
+	upstream, err := svcCtx.DialManager.Upstream(ctx, "echo")
+	require.NoError(t, err)
+	assert.Empty(t, upstream.Protoset)
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type VerifyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewVerifyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VerifyLogic {
+	return &VerifyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Verify checks the journaled calls of the method against the rule, the verification holds if the
+// rule held for exactly the given number of calls, or for at least one if none is given.
+func (l *VerifyLogic) Verify(req *types.VerifyRequest) (resp *types.VerifyResponse, err error) {
+	filter := journalFilter(req.MethodName, req.CaseName, req.Since, req.Until, req.Metadata)
+	verification, err := l.svcCtx.Journal.Verify(l.ctx, filter, req.Rule)
+	if err != nil {
+		return nil, errors.New("rule: " + err.Error())
+	}
+
+	resp = &types.VerifyResponse{
+		Total:      verification.Total,
+		Matched:    len(verification.Matches),
+		Matches:    make([]types.JournalEntry, 0, len(verification.Matches)),
+		NearMisses: make([]types.NearMiss, 0, len(verification.NearMisses)),
+	}
+	if req.Times != nil {
+		resp.Verified = resp.Matched == *req.Times
+	} else {
+		resp.Verified = resp.Matched > 0
+	}
+
+	for _, entry := range verification.Matches {
+		resp.Matches = append(resp.Matches, journalEntry(entry))
+	}
+	for _, miss := range verification.NearMisses {
+		resp.NearMisses = append(resp.NearMisses, types.NearMiss{
+			Entry:  journalEntry(miss.Entry),
+			Held:   miss.Held,
+			Failed: miss.Failed,
+		})
+	}
+
+	return resp, nil
+}
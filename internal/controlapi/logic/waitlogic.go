@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+type WaitLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWaitLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WaitLogic {
+	return &WaitLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Wait blocks until a call of the method the rule holds for is journaled, or the timeout in
+// milliseconds expires, at most the configured MaxWait. Calls journaled since the given time
+// count too, so that a call made before the wait started is not missed.
+func (l *WaitLogic) Wait(req *types.WaitRequest) (resp *types.WaitResponse, err error) {
+	if req.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	timeout := time.Duration(req.Timeout) * time.Millisecond
+	if maxWait := l.svcCtx.Config.Journal.MaxWait; maxWait > 0 && timeout > maxWait {
+		timeout = maxWait
+	}
+	ctx, cancel := context.WithTimeout(l.ctx, timeout)
+	defer cancel()
+
+	filter := journalFilter(req.MethodName, req.CaseName, req.Since, 0, req.Metadata)
+	entry, found, err := l.svcCtx.Journal.Wait(ctx, filter, req.Rule)
+	if err != nil {
+		return nil, errors.New("rule: " + err.Error())
+	}
+
+	resp = &types.WaitResponse{
+		Found: found,
+	}
+	if found {
+		resp.Entry = journalEntry(entry)
+	}
+
+	return resp, nil
+}
@@ -53,10 +53,48 @@ type CaseListResponse struct {
 }
 
 type Case struct {
-	MethodName string `json:"method_name"`
-	Name       string `json:"name"`
-	Rule       string `json:"rule, optional"`
-	Body       string `json:"body"`
+	MethodName string            `json:"method_name"`
+	Name       string            `json:"name"`
+	Rule       string            `json:"rule,optional"`
+	Body       string            `json:"body,optional"`
+	Status     CaseStatus        `json:"status,optional"`
+	Delay      CaseDelay         `json:"delay,optional"`
+	Headers    map[string]string `json:"headers,optional"`
+	Trailers   map[string]string `json:"trailers,optional"`
+	Stream     []StreamMessage   `json:"stream,optional"`
+	Script     []ScriptStep      `json:"script,optional"`
+	Template   bool              `json:"template,optional"`
+	Priority   int               `json:"priority,optional"`
+	CreatedAt  int64             `json:"created_at,optional"`
+}
+
+type StreamMessage struct {
+	Body  string `json:"body"`
+	Delay int    `json:"delay,optional"`
+}
+
+type ScriptStep struct {
+	Rule  string          `json:"rule,optional"`
+	Reply []StreamMessage `json:"reply,optional"`
+	End   bool            `json:"end,optional"`
+}
+
+type CaseStatus struct {
+	Code    int      `json:"code,optional"`
+	Message string   `json:"message,optional"`
+	Details []string `json:"details,optional"`
+}
+
+type CaseDelay struct {
+	Fixed       int               `json:"fixed,optional"`
+	Min         int               `json:"min,optional"`
+	Max         int               `json:"max,optional"`
+	Percentiles []DelayPercentile `json:"percentiles,optional"`
+}
+
+type DelayPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Delay      int     `json:"delay"`
 }
 
 type CaseDelRequest struct {
@@ -74,6 +112,14 @@ type CaseSetRequest struct {
 
 type CaseSetResponse struct {
 	BaseResponse
+	Errors []CaseError `json:"errors"`
+}
+
+type CaseError struct {
+	MethodName string `json:"method_name"`
+	Name       string `json:"name"`
+	Field      string `json:"field"`
+	Message    string `json:"message"`
 }
 
 type CaseDetailRequest struct {
@@ -86,6 +132,18 @@ type CaseDetailResponse struct {
 	Detail Case `json:"detail"`
 }
 
+type CaseFileListResponse struct {
+	BaseResponse
+	Files []CaseFile `json:"files"`
+}
+
+type CaseFile struct {
+	Path     string `json:"path"`
+	Cases    int    `json:"cases"`
+	Error    string `json:"error"`
+	LoadedAt int64  `json:"loaded_at"`
+}
+
 type UpstreamSetRequest struct {
 	Upstreams []RpcClientConfig `json:"upstreams"`
 }
@@ -102,13 +160,153 @@ type UpstreamDelResponse struct {
 	BaseResponse
 }
 
+type UpstreamProtosetRequest struct {
+	Name     string `json:"name"`
+	Protoset string `json:"protoset"`
+}
+
+type UpstreamProtosetResponse struct {
+	BaseResponse
+}
+
+type UpstreamDiffRequest struct {
+	Upstream string `json:"upstream,optional"`
+}
+
+type UpstreamDiffResponse struct {
+	BaseResponse
+	Schemas []UpstreamSchema `json:"schemas"`
+}
+
+type UpstreamSchema struct {
+	Upstream        string        `json:"upstream"`
+	RefreshedAt     int64         `json:"refreshed_at"`
+	Error           string        `json:"error"`
+	ChangedAt       int64         `json:"changed_at"`
+	AddedMethods    []string      `json:"added_methods"`
+	RemovedMethods  []string      `json:"removed_methods"`
+	ChangedMethods  []string      `json:"changed_methods"`
+	ChangedMessages []MessageDiff `json:"changed_messages"`
+}
+
+type MessageDiff struct {
+	Message       string   `json:"message"`
+	AddedFields   []string `json:"added_fields"`
+	RemovedFields []string `json:"removed_fields"`
+	ChangedFields []string `json:"changed_fields"`
+}
+
+type RecordStatusResponse struct {
+	BaseResponse
+	Methods   []string `json:"methods"`
+	Upstreams []string `json:"upstreams"`
+	Recorded  int      `json:"recorded"`
+}
+
+type RecordStartRequest struct {
+	Methods   []string `json:"methods,optional"`
+	Upstreams []string `json:"upstreams,optional"`
+}
+
+type RecordStartResponse struct {
+	BaseResponse
+}
+
+type RecordStopRequest struct {
+	Methods   []string `json:"methods,optional"`
+	Upstreams []string `json:"upstreams,optional"`
+}
+
+type RecordStopResponse struct {
+	BaseResponse
+}
+
+type JournalRequest struct {
+	MethodName string            `json:"method_name,optional"`
+	CaseName   string            `json:"case_name,optional"`
+	Since      int64             `json:"since,optional"`
+	Until      int64             `json:"until,optional"`
+	Metadata   map[string]string `json:"metadata,optional"`
+	Limit      int               `json:"limit,optional"`
+}
+
+type JournalResponse struct {
+	BaseResponse
+	Entries []JournalEntry `json:"entries"`
+}
+
+type JournalEntry struct {
+	ID         uint64              `json:"id"`
+	Time       int64               `json:"time"`
+	MethodName string              `json:"method_name"`
+	Metadata   map[string][]string `json:"metadata"`
+	Requests   []string            `json:"requests"`
+	MatchType  string              `json:"match_type"`
+	CaseName   string              `json:"case_name"`
+	Upstream   string              `json:"upstream"`
+	Code       int                 `json:"code"`
+	Message    string              `json:"message"`
+	Latency    float64             `json:"latency"`
+}
+
+type JournalResetResponse struct {
+	BaseResponse
+}
+
+type VerifyRequest struct {
+	MethodName string            `json:"method_name"`
+	Rule       string            `json:"rule,optional"`
+	CaseName   string            `json:"case_name,optional"`
+	Since      int64             `json:"since,optional"`
+	Until      int64             `json:"until,optional"`
+	Metadata   map[string]string `json:"metadata,optional"`
+	Times      *int              `json:"times,optional"`
+}
+
+type VerifyResponse struct {
+	BaseResponse
+	Verified   bool           `json:"verified"`
+	Total      int            `json:"total"`
+	Matched    int            `json:"matched"`
+	Matches    []JournalEntry `json:"matches"`
+	NearMisses []NearMiss     `json:"near_misses"`
+}
+
+type NearMiss struct {
+	Entry  JournalEntry `json:"entry"`
+	Held   int          `json:"held"`
+	Failed []string     `json:"failed"`
+}
+
+type WaitRequest struct {
+	MethodName string            `json:"method_name"`
+	Rule       string            `json:"rule,optional"`
+	CaseName   string            `json:"case_name,optional"`
+	Since      int64             `json:"since,optional"`
+	Metadata   map[string]string `json:"metadata,optional"`
+	Timeout    int64             `json:"timeout,default=10000"`
+}
+
+type WaitResponse struct {
+	BaseResponse
+	Found bool         `json:"found"`
+	Entry JournalEntry `json:"entry"`
+}
+
 type RpcClientConfig struct {
-	Name      string   `json:"name"`
-	Etcd      EtcdConf `json:"etcd,optional"`
-	Endpoints []string `json:"endpoints,optional"`
-	Target    string   `json:"target,optional"`
-	App       string   `json:"app,optional"`
-	Token     string   `json:"token,optional"`
+	Name            string            `json:"name"`
+	Etcd            EtcdConf          `json:"etcd,optional"`
+	Endpoints       []string          `json:"endpoints,optional"`
+	Target          string            `json:"target,optional"`
+	App             string            `json:"app,optional"`
+	Token           string            `json:"token,optional"`
+	Disabled        bool              `json:"disabled,optional"`
+	RefreshInterval int64             `json:"refresh_interval,optional"`
+	ProtosetFile    string            `json:"protoset_file,optional"`
+	Protos          map[string]string `json:"protos,optional"`
+	ProtoFiles      []string          `json:"proto_files,optional"`
+	ImportPaths     []string          `json:"import_paths,optional"`
+	Reflection      string            `json:"reflection,optional"`
 }
 
 type EtcdConf struct {
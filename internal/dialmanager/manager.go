@@ -3,78 +3,253 @@ package dialmanager
 import (
 	"context"
 	"errors"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
 	"github.com/zeromicro/go-zero/zrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
 	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
 )
 
+const (
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
 type Manager struct {
 	mutex sync.RWMutex
 
 	upstreams    map[string]*RpcClient
 	methodClient map[string]*RpcClient
+	retrying     map[string]chan struct{} // upstream name -> cancel signal of the retry loop
+	refreshing   map[string]chan struct{} // upstream name -> cancel signal of the schema refresh loop
+	schemas      map[string]SchemaStatus
+	registry     *Registry // merged descriptors of the upstreams, built on demand
 }
 
 func NewManager() *Manager {
 	return &Manager{
 		upstreams:    make(map[string]*RpcClient),
 		methodClient: make(map[string]*RpcClient),
+		retrying:     make(map[string]chan struct{}),
+		refreshing:   make(map[string]chan struct{}),
+		schemas:      make(map[string]SchemaStatus),
 	}
 }
 
 func (m *Manager) AddUpstream(ctx context.Context, upstreams []RpcClientConf) error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	for _, upstream := range upstreams {
+		m.cancelRetry(upstream.Name)
+
+		if err := m.addUpstream(ctx, upstream, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// AddUpstreamWithRetry adds the upstreams like AddUpstream, but the ones that cannot be
+// dialed yet are retried in the background until they succeed or are deleted.
+func (m *Manager) AddUpstreamWithRetry(ctx context.Context, upstreams []RpcClientConf) {
 	for _, upstream := range upstreams {
-		cli, err := zrpc.NewClient(upstream.RpcClientConf)
+		m.cancelRetry(upstream.Name)
+
+		if err := m.addUpstream(ctx, upstream, nil); err != nil {
+			logc.Errorw(ctx, "AddUpstreamWithRetry error, will retry in background",
+				logc.Field("upstream", upstream.Name), logc.Field("err", err.Error()))
+			m.retry(upstream)
+		}
+	}
+}
+
+// addUpstream dials the upstream and registers its methods. When called from a retry loop,
+// done must be the loop's cancel signal so that a canceled retry never registers the upstream.
+func (m *Manager) addUpstream(ctx context.Context, upstream RpcClientConf, done chan struct{}) error {
+	var cli zrpc.Client
+	if !upstream.Virtual() || !upstream.HasSchema() {
+		var err error
+		cli, err = zrpc.NewClient(upstream.RpcClientConf)
 		if err != nil {
 			logc.Errorw(ctx, "AddUpstream error", logc.Field("err", err.Error()))
 			return err
 		}
+	}
 
-		desc, err := parser.Parser(cli.Conn())
-		if err != nil {
+	desc, reflection, err := describe(upstream, cli)
+	if err != nil {
+		closeClient(ctx, cli)
+		return err
+	}
 
-			return err
+	client := &RpcClient{
+		RpcClientConf: upstream,
+		Client:        cli,
+		ServicesDesc:  desc,
+		Reflection:    reflection,
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if done != nil && m.retrying[upstream.Name] != done {
+		closeClient(ctx, cli)
+		return errRetryCanceled
+	}
+
+	if old, ok := m.upstreams[upstream.Name]; ok {
+		closeClient(ctx, old.Client)
+	}
+	m.removeMethods(upstream.Name)
+	for _, svc := range desc {
+		for _, method := range svc.Methods {
+			m.methodClient[method.FullName] = client
 		}
+	}
 
-		client := &RpcClient{
-			RpcClientConf: upstream,
-			Client:        cli,
-			ServicesDesc:  desc,
+	m.upstreams[upstream.Name] = client
+	m.registry = nil
+	delete(m.schemas, upstream.Name)
+	m.startRefresh(upstream)
+
+	return nil
+}
+
+// describe returns the services of the upstream, read from its protoset or compiled from its
+// protos if it has any, fetched by reflection otherwise. It also returns the reflection protocol
+// used, empty when none was.
+func describe(upstream RpcClientConf, cli zrpc.Client) ([]parser.ServiceDesc, string, error) {
+	var (
+		desc []parser.ServiceDesc
+		err  error
+	)
+	switch {
+	case len(upstream.Protoset) > 0:
+		desc, err = parser.ParseProtoset(upstream.Protoset)
+	case upstream.ProtosetFile != "":
+		var data []byte
+		if data, err = os.ReadFile(upstream.ProtosetFile); err == nil {
+			desc, err = parser.ParseProtoset(data)
 		}
+	case len(upstream.Protos) > 0 || len(upstream.ProtoFiles) > 0:
+		desc, err = parser.ParseProtos(upstream.Protos, upstream.ProtoFiles, upstream.ImportPaths)
+	default:
+		return parser.Parser(cli.Conn())
+	}
 
-		for _, svc := range desc {
-			for _, method := range svc.Methods {
-				m.methodClient[method.FullName] = client
+	return desc, "", err
+}
+
+func (m *Manager) retry(upstream RpcClientConf) {
+	done := make(chan struct{})
+
+	m.mutex.Lock()
+	m.retrying[upstream.Name] = done
+	m.mutex.Unlock()
+
+	threading.GoSafe(func() {
+		interval := minRetryInterval
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(interval):
+			}
+
+			ctx := context.Background()
+			err := m.addUpstream(ctx, upstream, done)
+			if err == errRetryCanceled {
+				return
 			}
+			if err != nil {
+				logc.Errorw(ctx, "retry AddUpstream error",
+					logc.Field("upstream", upstream.Name), logc.Field("err", err.Error()))
+
+				interval *= 2
+				if interval > maxRetryInterval {
+					interval = maxRetryInterval
+				}
+				continue
+			}
+
+			logc.Infow(ctx, "retry AddUpstream succeed", logc.Field("upstream", upstream.Name))
+
+			m.mutex.Lock()
+			if m.retrying[upstream.Name] == done {
+				delete(m.retrying, upstream.Name)
+			}
+			m.mutex.Unlock()
+			return
 		}
+	})
+}
 
-		m.upstreams[upstream.Name] = client
+// cancelRetry stops the background retry of the given upstream, it reports whether one was running.
+func (m *Manager) cancelRetry(name string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	done, ok := m.retrying[name]
+	if !ok {
+		return false
 	}
 
-	return nil
+	close(done)
+	delete(m.retrying, name)
+
+	return true
 }
 
 func (m *Manager) DelUpstream(ctx context.Context, name string) error {
+	retrying := m.cancelRetry(name)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	_, ok := m.upstreams[name]
+	client, ok := m.upstreams[name]
 	if !ok {
+		if retrying {
+			return nil
+		}
 		return ErrNotFound
 	}
 
+	closeClient(ctx, client.Client)
+	m.removeMethods(name)
 	delete(m.upstreams, name)
+	m.registry = nil
+	m.stopRefresh(name)
+	delete(m.schemas, name)
 
 	return nil
 }
 
+// closeClient closes the connection of an upstream that is no longer used, virtual upstreams have
+// none.
+func closeClient(ctx context.Context, cli zrpc.Client) {
+	if cli == nil {
+		return
+	}
+
+	if err := cli.Conn().Close(); err != nil {
+		logc.Errorw(ctx, "close upstream connection error", logc.Field("err", err.Error()))
+	}
+}
+
+// removeMethods drops the method routes of the given upstream, the caller must hold the lock.
+func (m *Manager) removeMethods(name string) {
+	for method, client := range m.methodClient {
+		if client.Name == name {
+			delete(m.methodClient, method)
+		}
+	}
+}
+
 func (m *Manager) Upstream(ctx context.Context, name string) (RpcClientConf, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -87,6 +262,20 @@ func (m *Manager) Upstream(ctx context.Context, name string) (RpcClientConf, err
 	return cli.RpcClientConf, nil
 }
 
+// Reflection returns the server reflection protocol the schema of the upstream was fetched with,
+// empty if it came from a protoset or .proto sources, or if the upstream is not registered.
+func (m *Manager) Reflection(ctx context.Context, name string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	cli, ok := m.upstreams[name]
+	if !ok {
+		return ""
+	}
+
+	return cli.Reflection
+}
+
 func (m *Manager) Upstreams(ctx context.Context) ([]RpcClientConf, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -136,6 +325,19 @@ func (m *Manager) MethodDetail(ctx context.Context, method string) (parser.Metho
 	return parser.MethodDesc{}, ErrNotFound
 }
 
+// MethodUpstream returns the name of the upstream the method is routed to.
+func (m *Manager) MethodUpstream(ctx context.Context, method string) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	cli, ok := m.methodClient[method]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return cli.Name, nil
+}
+
 func (m *Manager) UpstreamClient(ctx context.Context, name string) (grpc.ClientConnInterface, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -144,6 +346,31 @@ func (m *Manager) UpstreamClient(ctx context.Context, name string) (grpc.ClientC
 	if !ok {
 		return nil, ErrNotFound
 	}
+	if cli.Disabled {
+		return nil, ErrDisabled
+	}
+	if cli.Client == nil {
+		return nil, ErrVirtual
+	}
 
 	return cli.Conn(), nil
 }
+
+// Available reports whether calls of the given method can be forwarded, that is its upstream
+// is known, not virtual nor disabled and its connection is not failing.
+func (m *Manager) Available(ctx context.Context, method string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	cli, ok := m.methodClient[method]
+	if !ok || cli.Disabled || cli.Client == nil {
+		return false
+	}
+
+	switch cli.Conn().GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	default:
+		return true
+	}
+}
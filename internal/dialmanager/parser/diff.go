@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+)
+
+type (
+	// SchemaDiff lists what changed between two descriptions of the same upstream.
+	SchemaDiff struct {
+		AddedMethods    []string
+		RemovedMethods  []string
+		ChangedMethods  []string // new signature of the methods whose request, response or streaming changed
+		ChangedMessages []MessageDiff
+	}
+	// MessageDiff lists the changed fields of a message, each one printed like "repeated string tags = 4".
+	MessageDiff struct {
+		Message       string
+		AddedFields   []string
+		RemovedFields []string
+		ChangedFields []string // new definition of the fields whose number was kept
+	}
+)
+
+// Empty reports whether nothing changed.
+func (d SchemaDiff) Empty() bool {
+	return len(d.AddedMethods) == 0 && len(d.RemovedMethods) == 0 && len(d.ChangedMethods) == 0 &&
+		len(d.ChangedMessages) == 0
+}
+
+// Diff compares two descriptions of the same upstream: the methods, and the fields of every
+// message reachable from the request and response of the methods found in both.
+func Diff(old, new []ServiceDesc) SchemaDiff {
+	var diff SchemaDiff
+
+	oldMethods, newMethods := methodsByName(old), methodsByName(new)
+	oldMessages, newMessages := make(map[string]*desc.MessageDescriptor), make(map[string]*desc.MessageDescriptor)
+	for name, method := range newMethods {
+		oldMethod, ok := oldMethods[name]
+		if !ok {
+			diff.AddedMethods = append(diff.AddedMethods, name)
+			continue
+		}
+		if signature(oldMethod) != signature(method) {
+			diff.ChangedMethods = append(diff.ChangedMethods, signature(method))
+		}
+
+		collectMessages(oldMethod.In.RawDesc, oldMessages)
+		collectMessages(oldMethod.Out.RawDesc, oldMessages)
+		collectMessages(method.In.RawDesc, newMessages)
+		collectMessages(method.Out.RawDesc, newMessages)
+	}
+	for name := range oldMethods {
+		if _, ok := newMethods[name]; !ok {
+			diff.RemovedMethods = append(diff.RemovedMethods, name)
+		}
+	}
+
+	for name, md := range newMessages {
+		oldMd, ok := oldMessages[name]
+		if !ok {
+			continue
+		}
+		if md := diffMessage(oldMd, md); md != nil {
+			diff.ChangedMessages = append(diff.ChangedMessages, *md)
+		}
+	}
+
+	sort.Strings(diff.AddedMethods)
+	sort.Strings(diff.RemovedMethods)
+	sort.Strings(diff.ChangedMethods)
+	sort.Slice(diff.ChangedMessages, func(i, j int) bool {
+		return diff.ChangedMessages[i].Message < diff.ChangedMessages[j].Message
+	})
+
+	return diff
+}
+
+func methodsByName(svcs []ServiceDesc) map[string]MethodDesc {
+	methods := make(map[string]MethodDesc)
+	for _, svc := range svcs {
+		for _, method := range svc.Methods {
+			methods[method.FullName] = method
+		}
+	}
+
+	return methods
+}
+
+// signature prints the method like "/pkg.Service/Method(stream pkg.Req) returns (pkg.Resp)".
+func signature(method MethodDesc) string {
+	stream := func(streaming bool) string {
+		if streaming {
+			return "stream "
+		}
+		return ""
+	}
+
+	return fmt.Sprintf("%s(%s%s) returns (%s%s)", method.FullName, stream(method.ClientStreaming), method.In.FullName,
+		stream(method.ServerStreaming), method.Out.FullName)
+}
+
+// collectMessages adds md and the message types of its fields, recursively, to messages.
+func collectMessages(md *desc.MessageDescriptor, messages map[string]*desc.MessageDescriptor) {
+	if md == nil {
+		return
+	}
+	if _, ok := messages[md.GetFullyQualifiedName()]; ok {
+		return
+	}
+
+	messages[md.GetFullyQualifiedName()] = md
+	for _, fd := range md.GetFields() {
+		collectMessages(fd.GetMessageType(), messages)
+	}
+}
+
+func diffMessage(old, new *desc.MessageDescriptor) *MessageDiff {
+	diff := MessageDiff{
+		Message: new.GetFullyQualifiedName(),
+	}
+
+	for _, fd := range new.GetFields() {
+		oldFd := old.FindFieldByNumber(fd.GetNumber())
+		switch {
+		case oldFd == nil:
+			diff.AddedFields = append(diff.AddedFields, fieldString(fd))
+		case fieldString(oldFd) != fieldString(fd):
+			diff.ChangedFields = append(diff.ChangedFields, fieldString(fd))
+		}
+	}
+	for _, fd := range old.GetFields() {
+		if new.FindFieldByNumber(fd.GetNumber()) == nil {
+			diff.RemovedFields = append(diff.RemovedFields, fieldString(fd))
+		}
+	}
+
+	if len(diff.AddedFields) == 0 && len(diff.RemovedFields) == 0 && len(diff.ChangedFields) == 0 {
+		return nil
+	}
+
+	return &diff
+}
+
+// fieldString prints the field definition the way it is declared in a .proto file.
+func fieldString(fd *desc.FieldDescriptor) string {
+	return fmt.Sprintf("%s %s = %d", fieldType(fd), fd.GetName(), fd.GetNumber())
+}
+
+func fieldType(fd *desc.FieldDescriptor) string {
+	if fd.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldType(fd.GetMapKeyType()), fieldType(fd.GetMapValueType()))
+	}
+
+	var typ string
+	switch {
+	case fd.GetMessageType() != nil:
+		typ = fd.GetMessageType().GetFullyQualifiedName()
+	case fd.GetEnumType() != nil:
+		typ = fd.GetEnumType().GetFullyQualifiedName()
+	default:
+		typ = strings.ToLower(strings.TrimPrefix(fd.GetType().String(), "TYPE_"))
+	}
+	if fd.IsRepeated() {
+		typ = "repeated " + typ
+	}
+
+	return typ
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	fakeMaxDepth    = 3 // nested messages deeper than this are left unset, it also stops recursive types
+	fakeMinRepeated = 1
+	fakeMaxRepeated = 3
+)
+
+var (
+	fakeFirstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Iris", "Jack"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Clark"}
+	fakeCities     = []string{"London", "Paris", "Berlin", "Tokyo", "Shanghai", "New York", "Sydney", "Toronto"}
+	fakeCountries  = []string{"GB", "FR", "DE", "JP", "CN", "US", "AU", "CA"}
+	fakeStreets    = []string{"Main Street", "High Street", "Park Avenue", "Oak Road", "Station Road", "Church Lane"}
+	fakeWords      = []string{"alpha", "bravo", "delta", "echo", "golf", "hotel", "lima", "nova", "orbit", "pixel",
+		"quartz", "river", "sigma", "tango", "vector", "zephyr"}
+)
+
+// Fake generates a message of the given type filled with random but plausible values. Enums take
+// one of their declared values, repeated and map fields get a few entries, one field of each oneof
+// is set, Timestamp and Duration get sensible values and field names like email or created_at
+// pick a matching generator.
+func Fake(md *desc.MessageDescriptor) proto.Message {
+	return fakeMessage(md, 0)
+}
+
+func fakeMessage(md *desc.MessageDescriptor, depth int) proto.Message {
+	switch md.GetFullyQualifiedName() {
+	case "google.protobuf.Timestamp":
+		return timestamppb.New(time.Now().Add(-time.Duration(rand.Int63n(int64(30 * 24 * time.Hour)))).Truncate(time.Second))
+	case "google.protobuf.Duration":
+		return durationpb.New(time.Duration(1+rand.Int63n(3600)) * time.Second)
+	}
+
+	msg := dynamic.NewMessage(md)
+	if md.GetFullyQualifiedName() == "google.protobuf.Any" {
+		// there is no sensible type to pack, an empty Any is still valid
+		return msg
+	}
+
+	for _, fd := range md.GetFields() {
+		if od := fd.GetOneOf(); od != nil && !od.IsSynthetic() {
+			continue
+		}
+		fakeField(msg, fd, depth)
+	}
+	for _, od := range md.GetOneOfs() {
+		if od.IsSynthetic() {
+			continue
+		}
+		choices := od.GetChoices()
+		fakeField(msg, choices[rand.Intn(len(choices))], depth)
+	}
+
+	return msg
+}
+
+func fakeField(msg *dynamic.Message, fd *desc.FieldDescriptor, depth int) {
+	n := fakeMinRepeated + rand.Intn(fakeMaxRepeated-fakeMinRepeated+1)
+	switch {
+	case fd.IsMap():
+		kfd, vfd := fd.GetMapKeyType(), fd.GetMapValueType()
+		for i := 0; i < n; i++ {
+			v, ok := fakeValue(vfd, fd.GetName(), depth+1)
+			if !ok {
+				return
+			}
+			_ = msg.TryPutMapField(fd, fakeMapKey(kfd, fd.GetName(), i), v)
+		}
+	case fd.IsRepeated():
+		for i := 0; i < n; i++ {
+			v, ok := fakeValue(fd, fd.GetName(), depth+1)
+			if !ok {
+				return
+			}
+			_ = msg.TryAddRepeatedField(fd, v)
+		}
+	default:
+		if v, ok := fakeValue(fd, fd.GetName(), depth+1); ok {
+			_ = msg.TrySetField(fd, v)
+		}
+	}
+}
+
+// fakeMapKey returns the i-th key of a map field, string keys are suffixed so they never collide.
+func fakeMapKey(kfd *desc.FieldDescriptor, name string, i int) interface{} {
+	switch kfd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return fmt.Sprintf("%s_%d", fakeWords[rand.Intn(len(fakeWords))], i+1)
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return i%2 == 0
+	default:
+		v, _ := fakeValue(kfd, name, 0)
+		if n, ok := v.(int32); ok {
+			return n + int32(i)*1000
+		}
+		if n, ok := v.(int64); ok {
+			return n + int64(i)*1000
+		}
+		if n, ok := v.(uint32); ok {
+			return n + uint32(i)*1000
+		}
+		if n, ok := v.(uint64); ok {
+			return n + uint64(i)*1000
+		}
+		return v
+	}
+}
+
+// fakeValue returns a single value of the field type, name is the field name the generator is chosen by.
+func fakeValue(fd *desc.FieldDescriptor, name string, depth int) (interface{}, bool) {
+	name = strings.ToLower(name)
+
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		if depth > fakeMaxDepth {
+			return nil, false
+		}
+		return fakeMessage(fd.GetMessageType(), depth), true
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		values := fd.GetEnumType().GetValues()
+		if len(values) > 1 && values[0].GetNumber() == 0 {
+			// the zero value is usually UNSPECIFIED, prefer a meaningful one
+			values = values[1:]
+		}
+		return values[rand.Intn(len(values))].GetNumber(), true
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return fakeString(name), true
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		b := make([]byte, 8)
+		rand.Read(b)
+		return b, true
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return rand.Intn(2) == 0, true
+	case descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		return fakeFloat(name), true
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		return float32(fakeFloat(name)), true
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64, descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return fakeInt(name, true), true
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		return uint64(fakeInt(name, true)), true
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return int32(fakeInt(name, false)), true
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		return uint32(fakeInt(name, false)), true
+	}
+
+	return nil, false
+}
+
+func fakeString(name string) string {
+	pick := func(vs []string) string {
+		return vs[rand.Intn(len(vs))]
+	}
+	first, last := pick(fakeFirstNames), pick(fakeLastNames)
+
+	switch {
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("%s.%s@example.com", strings.ToLower(first), strings.ToLower(last))
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile"):
+		return fmt.Sprintf("+1555%07d", rand.Intn(10000000))
+	case strings.Contains(name, "avatar") || strings.Contains(name, "image") || strings.Contains(name, "photo"):
+		return fmt.Sprintf("https://example.com/images/%s.png", RandStringRunes(8))
+	case strings.Contains(name, "url") || strings.Contains(name, "link") || strings.Contains(name, "website"):
+		return fmt.Sprintf("https://example.com/%s", strings.ToLower(RandStringRunes(8)))
+	case name == "id" || strings.HasSuffix(name, "_id") || strings.Contains(name, "uuid"):
+		return uuid.NewString()
+	case strings.Contains(name, "first_name"):
+		return first
+	case strings.Contains(name, "last_name"):
+		return last
+	case strings.Contains(name, "username") || strings.Contains(name, "nickname"):
+		return fmt.Sprintf("%s%d", strings.ToLower(first), rand.Intn(1000))
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	case strings.Contains(name, "city"):
+		return pick(fakeCities)
+	case strings.Contains(name, "country"):
+		return pick(fakeCountries)
+	case strings.Contains(name, "address") || strings.Contains(name, "street"):
+		return fmt.Sprintf("%d %s", 1+rand.Intn(200), pick(fakeStreets))
+	case strings.Contains(name, "zip") || strings.Contains(name, "postal"):
+		return fmt.Sprintf("%05d", rand.Intn(100000))
+	case name == "ip" || strings.HasSuffix(name, "_ip") || strings.HasPrefix(name, "ip_"):
+		return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), 1+rand.Intn(254))
+	case strings.HasSuffix(name, "_at") || strings.Contains(name, "time"):
+		return time.Now().Add(-time.Duration(rand.Int63n(int64(30 * 24 * time.Hour)))).UTC().Format(time.RFC3339)
+	case strings.Contains(name, "date") || name == "birthday":
+		return time.Now().AddDate(0, 0, -rand.Intn(3650)).Format("2006-01-02")
+	case strings.Contains(name, "currency"):
+		return "USD"
+	case strings.Contains(name, "lang") || strings.Contains(name, "locale"):
+		return "en-US"
+	case strings.Contains(name, "color") || strings.Contains(name, "colour"):
+		return fmt.Sprintf("#%06x", rand.Intn(0x1000000))
+	case strings.Contains(name, "token") || strings.Contains(name, "secret"):
+		return RandStringRunes(32)
+	case strings.Contains(name, "title"):
+		return capitalize(pick(fakeWords) + " " + pick(fakeWords))
+	case strings.Contains(name, "desc") || strings.Contains(name, "content") || strings.Contains(name, "comment") ||
+		strings.Contains(name, "message") || strings.Contains(name, "text") || strings.Contains(name, "summary"):
+		return fmt.Sprintf("%s %s %s %s.", capitalize(pick(fakeWords)), pick(fakeWords), pick(fakeWords), pick(fakeWords))
+	default:
+		return pick(fakeWords)
+	}
+}
+
+func fakeInt(name string, wide bool) int64 {
+	between := func(min, max int64) int64 {
+		return min + rand.Int63n(max-min+1)
+	}
+
+	switch {
+	case wide && (strings.HasSuffix(name, "_at") || strings.Contains(name, "time")):
+		ts := time.Now().Add(-time.Duration(rand.Int63n(int64(30 * 24 * time.Hour))))
+		if strings.Contains(name, "ms") || strings.Contains(name, "milli") {
+			return ts.UnixMilli()
+		}
+		return ts.Unix()
+	case strings.Contains(name, "age"):
+		return between(18, 80)
+	case strings.Contains(name, "year"):
+		return between(1990, int64(time.Now().Year()))
+	case strings.Contains(name, "port"):
+		return between(1024, 65535)
+	case strings.Contains(name, "price") || strings.Contains(name, "amount") || strings.Contains(name, "balance"):
+		return between(100, 100000)
+	case strings.Contains(name, "count") || strings.Contains(name, "total") || strings.Contains(name, "num") ||
+		strings.Contains(name, "size") || strings.Contains(name, "quantity"):
+		return between(0, 100)
+	case name == "id" || strings.HasSuffix(name, "_id"):
+		return between(1, 1000000)
+	default:
+		return between(1, 1000)
+	}
+}
+
+func fakeFloat(name string) float64 {
+	round := func(f float64) float64 {
+		return float64(int64(f*100)) / 100
+	}
+
+	switch {
+	case strings.Contains(name, "lat"):
+		return round(rand.Float64()*180 - 90)
+	case strings.Contains(name, "lng") || strings.Contains(name, "lon"):
+		return round(rand.Float64()*360 - 180)
+	case strings.Contains(name, "percent"):
+		return round(rand.Float64() * 100)
+	case strings.Contains(name, "rate") || strings.Contains(name, "ratio") || strings.Contains(name, "score"):
+		return round(rand.Float64())
+	case strings.Contains(name, "price") || strings.Contains(name, "amount") || strings.Contains(name, "balance"):
+		return round(1 + rand.Float64()*999)
+	default:
+		return round(rand.Float64() * 1000)
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/fullstorydev/grpcurl"
 	"github.com/golang/protobuf/jsonpb"
@@ -10,7 +11,10 @@ import (
 	"github.com/jhump/protoreflect/desc/protoprint"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
 	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
 )
 
 type (
@@ -18,13 +22,16 @@ type (
 		Name     string
 		FullName string
 		Methods  []MethodDesc
+		RawDesc  *desc.ServiceDescriptor
 	}
 	MethodDesc struct {
-		Name      string
-		FullName  string
-		ProtoDesc string
-		In        FieldDesc
-		Out       FieldDesc
+		Name            string
+		FullName        string
+		ProtoDesc       string
+		ClientStreaming bool
+		ServerStreaming bool
+		In              FieldDesc
+		Out             FieldDesc
 	}
 	FieldDesc struct {
 		Name      string
@@ -35,12 +42,34 @@ type (
 	}
 )
 
-func Parser(cc grpc.ClientConnInterface) ([]ServiceDesc, error) {
-	source, err := createDescriptorSource(cc)
+// Server reflection protocols an upstream schema can be fetched with.
+const (
+	ReflectionV1      = "v1"
+	ReflectionV1Alpha = "v1alpha"
+)
+
+// reflectionTimeout bounds the server reflection calls, so that an upstream that never answers
+// does not block adding or refreshing it.
+const reflectionTimeout = 10 * time.Second
+
+// Parser describes the services of cc by server reflection, it also returns the protocol used.
+func Parser(cc grpc.ClientConnInterface) ([]ServiceDesc, string, error) {
+	source, protocol, closer, err := createDescriptorSource(cc)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	defer closer()
+
+	desc, err := parseSource(source)
+	if err != nil {
+		return nil, "", err
 	}
 
+	return desc, protocol, nil
+}
+
+// parseSource describes every service the source knows about.
+func parseSource(source grpcurl.DescriptorSource) ([]ServiceDesc, error) {
 	svcs, err := source.ListServices()
 	if err != nil {
 		return nil, err
@@ -57,7 +86,7 @@ func Parser(cc grpc.ClientConnInterface) ([]ServiceDesc, error) {
 		}
 		switch val := d.(type) {
 		case *desc.ServiceDescriptor:
-
+			s.RawDesc = val
 			svcMethods := val.GetMethods()
 			s.Methods = make([]MethodDesc, 0, len(svcMethods))
 			pt := &protoprint.Printer{}
@@ -73,9 +102,11 @@ func Parser(cc grpc.ClientConnInterface) ([]ServiceDesc, error) {
 				outProto, _ := pt.PrintProtoToString(method.GetOutputType())
 
 				m := MethodDesc{
-					Name:      method.GetName(),
-					FullName:  fmt.Sprintf("/%s/%s", svc, method.GetName()),
-					ProtoDesc: mProto,
+					Name:            method.GetName(),
+					FullName:        fmt.Sprintf("/%s/%s", svc, method.GetName()),
+					ProtoDesc:       mProto,
+					ClientStreaming: method.IsClientStreaming(),
+					ServerStreaming: method.IsServerStreaming(),
 					In: FieldDesc{
 						Name:      method.GetInputType().GetName(),
 						FullName:  method.GetInputType().GetFullyQualifiedName(),
@@ -100,12 +131,52 @@ func Parser(cc grpc.ClientConnInterface) ([]ServiceDesc, error) {
 	return ss, nil
 }
 
-func createDescriptorSource(cc grpc.ClientConnInterface) (grpcurl.DescriptorSource, error) {
-	var source grpcurl.DescriptorSource
+// createDescriptorSource returns the source describing cc by server reflection, closer releases
+// the reflection stream once the source is no longer used. The stream lives at most
+// reflectionTimeout, since the schema is refreshed periodically over the same connection.
+func createDescriptorSource(cc grpc.ClientConnInterface) (grpcurl.DescriptorSource, string, func(), error) {
+	protocol, err := reflectionProtocol(cc)
+	if err != nil {
+		return nil, "", nil, err
+	}
 
-	refCli := grpc_reflection_v1alpha.NewServerReflectionClient(cc)
-	client := grpcreflect.NewClient(context.Background(), refCli)
-	source = grpcurl.DescriptorSourceFromServer(context.Background(), client)
+	ctx, cancel := context.WithTimeout(context.Background(), reflectionTimeout)
+	var client *grpcreflect.Client
+	if protocol == ReflectionV1 {
+		client = grpcreflect.NewClientAuto(ctx, cc)
+	} else {
+		refCli := grpc_reflection_v1alpha.NewServerReflectionClient(cc)
+		client = grpcreflect.NewClientV1Alpha(ctx, refCli)
+	}
+	closer := func() {
+		client.Reset()
+		cancel()
+	}
 
-	return source, nil
+	return grpcurl.DescriptorSourceFromServer(ctx, client), protocol, closer, nil
+}
+
+// reflectionProtocol negotiates the server reflection protocol of cc, v1 unless the server only
+// implements v1alpha.
+func reflectionProtocol(cc grpc.ClientConnInterface) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reflectionTimeout)
+	defer cancel()
+
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(cc).ServerReflectionInfo(ctx)
+	if err == nil {
+		// a send error is only io.EOF, the status of the stream is returned by Recv
+		_ = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		})
+		_, err = stream.Recv()
+	}
+
+	switch status.Code(err) {
+	case codes.OK:
+		return ReflectionV1, nil
+	case codes.Unimplemented:
+		return ReflectionV1Alpha, nil
+	default:
+		return "", err
+	}
 }
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ParseProtos compiles .proto files in-process and describes the services they declare, so that
+// no live reflection is needed. sources holds uploaded file contents by file name, files names
+// the ones to read from disk. Both, as well as their imports, are resolved against importPaths
+// and the working directory, the well-known google/protobuf imports are always available.
+func ParseProtos(sources map[string]string, files, importPaths []string) ([]ServiceDesc, error) {
+	names := make([]string, 0, len(sources)+len(files))
+	seen := make(map[string]bool)
+	for _, name := range append(sortedKeys(sources), files...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no proto files given")
+	}
+
+	p := protoparse.Parser{
+		// "." first, so that uploaded sources are found by the exact name they were given
+		ImportPaths: append([]string{"."}, importPaths...),
+		Accessor: func(filename string) (io.ReadCloser, error) {
+			if src, ok := sources[filename]; ok {
+				return io.NopCloser(strings.NewReader(src)), nil
+			}
+			return os.Open(filename)
+		},
+	}
+	fds, err := p.ParseFiles(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := grpcurl.DescriptorSourceFromFileDescriptors(fds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSource(source)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ParseProtoset describes the services of a serialized FileDescriptorSet, as written by
+// protoc --descriptor_set_out or buf build. It must include the imports of its files.
+func ParseProtoset(data []byte) ([]ServiceDesc, error) {
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		return nil, fmt.Errorf("invalid protoset: %w", err)
+	}
+
+	source, err := grpcurl.DescriptorSourceFromFileDescriptorSet(&fds)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSource(source)
+}
@@ -0,0 +1,120 @@
+package dialmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+)
+
+// startRefresh replaces the schema refresh loop of the upstream by a new one if it has a refresh
+// interval, the caller must hold the lock.
+func (m *Manager) startRefresh(upstream RpcClientConf) {
+	m.stopRefresh(upstream.Name)
+	if upstream.RefreshInterval <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	m.refreshing[upstream.Name] = done
+
+	threading.GoSafe(func() {
+		ticker := time.NewTicker(upstream.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.refresh(context.Background(), upstream.Name, done)
+			}
+		}
+	})
+}
+
+// stopRefresh stops the schema refresh loop of the upstream, the caller must hold the lock.
+func (m *Manager) stopRefresh(name string) {
+	if done, ok := m.refreshing[name]; ok {
+		close(done)
+		delete(m.refreshing, name)
+	}
+}
+
+// refresh describes the upstream again and, if its schema changed, swaps its services and method
+// routes at once. Routes another upstream took over in the meantime are left to it.
+func (m *Manager) refresh(ctx context.Context, name string, done chan struct{}) {
+	m.mutex.RLock()
+	client, ok := m.upstreams[name]
+	m.mutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	desc, reflection, err := describe(client.RpcClientConf, client.Client)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.refreshing[name] != done || m.upstreams[name] != client {
+		// the upstream was replaced or deleted while being described
+		return
+	}
+
+	status := m.schemas[name]
+	status.RefreshedAt = time.Now()
+	if err != nil {
+		status.Error = err.Error()
+		m.schemas[name] = status
+		logc.Errorw(ctx, "refresh upstream schema error", logc.Field("upstream", name), logc.Field("err", err.Error()))
+		return
+	}
+	status.Error = ""
+
+	diff := parser.Diff(client.ServicesDesc, desc)
+	if diff.Empty() {
+		m.schemas[name] = status
+		return
+	}
+	status.ChangedAt = status.RefreshedAt
+	status.Diff = diff
+	m.schemas[name] = status
+
+	refreshed := &RpcClient{
+		RpcClientConf: client.RpcClientConf,
+		Client:        client.Client,
+		ServicesDesc:  desc,
+		Reflection:    reflection,
+	}
+	m.removeMethods(name)
+	for _, svc := range desc {
+		for _, method := range svc.Methods {
+			if _, ok := m.methodClient[method.FullName]; !ok {
+				m.methodClient[method.FullName] = refreshed
+			}
+		}
+	}
+	m.upstreams[name] = refreshed
+	m.registry = nil
+
+	logc.Infow(ctx, "upstream schema changed", logc.Field("upstream", name),
+		logc.Field("added_methods", diff.AddedMethods), logc.Field("removed_methods", diff.RemovedMethods),
+		logc.Field("changed_methods", diff.ChangedMethods), logc.Field("changed_messages", diff.ChangedMessages))
+}
+
+// Schemas returns the outcome of the schema refreshes by upstream name, only upstreams that were
+// refreshed at least once are included.
+func (m *Manager) Schemas(ctx context.Context) map[string]SchemaStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	schemas := make(map[string]SchemaStatus, len(m.schemas))
+	for name, status := range m.schemas {
+		schemas[name] = status
+	}
+
+	return schemas
+}
@@ -0,0 +1,83 @@
+package dialmanager
+
+import (
+	"context"
+	"sort"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Registry holds the descriptors of every upstream, virtual ones included, merged together.
+type Registry struct {
+	Files *protoregistry.Files
+	Types *protoregistry.Types
+}
+
+// Registry returns the merged descriptors of the upstreams. A file or symbol declared by several
+// upstreams is taken from the first one by name. It is rebuilt only after upstreams changed.
+func (m *Manager) Registry(ctx context.Context) *Registry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.registry != nil {
+		return m.registry
+	}
+
+	names := make([]string, 0, len(m.upstreams))
+	for name := range m.upstreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r := &Registry{
+		Files: new(protoregistry.Files),
+		Types: new(protoregistry.Types),
+	}
+	for _, name := range names {
+		for _, svc := range m.upstreams[name].ServicesDesc {
+			if svc.RawDesc != nil {
+				r.register(ctx, svc.RawDesc.GetFile().UnwrapFile())
+			}
+		}
+	}
+	m.registry = r
+
+	return r
+}
+
+// register adds the file after its imports, skipping the ones already known by path.
+func (r *Registry) register(ctx context.Context, fd protoreflect.FileDescriptor) {
+	if _, err := r.Files.FindFileByPath(fd.Path()); err == nil {
+		return
+	}
+
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		r.register(ctx, imports.Get(i).FileDescriptor)
+	}
+
+	if err := r.Files.RegisterFile(fd); err != nil {
+		// another upstream declared the same symbols in a differently named file
+		logc.Infow(ctx, "Registry skip file", logc.Field("file", fd.Path()), logc.Field("err", err.Error()))
+		return
+	}
+
+	r.registerExtensions(fd.Extensions())
+	r.registerMessageExtensions(fd.Messages())
+}
+
+func (r *Registry) registerMessageExtensions(mds protoreflect.MessageDescriptors) {
+	for i := 0; i < mds.Len(); i++ {
+		r.registerExtensions(mds.Get(i).Extensions())
+		r.registerMessageExtensions(mds.Get(i).Messages())
+	}
+}
+
+func (r *Registry) registerExtensions(xds protoreflect.ExtensionDescriptors) {
+	for i := 0; i < xds.Len(); i++ {
+		_ = r.Types.RegisterExtension(dynamicpb.NewExtensionType(xds.Get(i)))
+	}
+}
@@ -1,6 +1,8 @@
 package dialmanager
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/zrpc"
 
 	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
@@ -8,7 +10,17 @@ import (
 
 type (
 	RpcClientConf struct {
-		Name string
+		Name     string
+		Disabled bool `json:",optional"` // serve cases and fakes only, never forward to it
+		// the schema is described again at this interval and swapped if it changed, 0 disables it
+		RefreshInterval time.Duration `json:",optional"`
+		// the schema is read from a protoset or compiled from .proto sources instead of fetched
+		// by reflection, without any Endpoints, Target or Etcd the upstream is virtual and never dialed
+		Protoset     []byte            `json:",optional"` // serialized FileDescriptorSet, takes precedence
+		ProtosetFile string            `json:",optional"` // file holding a serialized FileDescriptorSet
+		Protos       map[string]string `json:",optional"` // uploaded sources by file name
+		ProtoFiles   []string          `json:",optional"` // files read from disk
+		ImportPaths  []string          `json:",optional"`
 		zrpc.RpcClientConf
 	}
 
@@ -17,5 +29,24 @@ type (
 		zrpc.Client
 
 		ServicesDesc []parser.ServiceDesc
+		Reflection   string // protocol the schema was fetched with, empty if it was not by reflection
+	}
+
+	// SchemaStatus is the outcome of the schema refreshes of an upstream.
+	SchemaStatus struct {
+		RefreshedAt time.Time // last refresh
+		Error       string    // error of the last refresh, the previous schema is kept
+		ChangedAt   time.Time // last refresh that found the schema changed
+		Diff        parser.SchemaDiff
 	}
 )
+
+// Virtual reports whether the upstream has no backend to dial, its calls can only be mocked.
+func (c RpcClientConf) Virtual() bool {
+	return len(c.Endpoints) == 0 && c.Target == "" && len(c.Etcd.Hosts) == 0
+}
+
+// HasSchema reports whether the schema of the upstream comes from a protoset or .proto sources.
+func (c RpcClientConf) HasSchema() bool {
+	return len(c.Protoset) > 0 || c.ProtosetFile != "" || len(c.Protos) > 0 || len(c.ProtoFiles) > 0
+}
@@ -4,4 +4,10 @@ import (
 	"errors"
 )
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound = errors.New("not found")
+	ErrDisabled = errors.New("upstream disabled")
+	ErrVirtual  = errors.New("virtual upstream has no backend")
+
+	errRetryCanceled = errors.New("retry canceled")
+)
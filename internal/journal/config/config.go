@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type JournalConfig struct {
+	Size    int           `json:",default=1000"` // number of calls kept, the oldest ones are dropped first, 0 disables the journal
+	Path    string        `json:",optional"`     // json lines file the calls are also appended to and reloaded from, empty keeps them in memory only
+	MaxWait time.Duration `json:",default=1m"`   // longest a /wait request blocks, longer timeouts are cut to it
+}
@@ -0,0 +1,395 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/journal/config"
+)
+
+// maxLineSize bounds the size of an entry reloaded from the journal file.
+const maxLineSize = 16 << 20
+
+// requestMarshaler gives the json form rules query, so that journaled requests read the same.
+var requestMarshaler = &jsonpb.Marshaler{OrigName: true, EnumsAsInts: true, EmitDefaults: true}
+
+type (
+	// MethodResolver looks up the descriptor and the upstream of a method, it is implemented by
+	// dialmanager.Manager.
+	MethodResolver interface {
+		MethodDetail(ctx context.Context, method string) (parser.MethodDesc, error)
+		MethodUpstream(ctx context.Context, method string) (string, error)
+	}
+
+	// Call is a call the proxy handled, as reported by its handler.
+	Call struct {
+		FullMethodName string
+		MD             metadata.MD
+		Reqs           [][]byte // request messages received before matching
+		MatchType      string
+		CaseName       string
+		Forwarded      bool
+		Err            error
+		Start          time.Time
+	}
+
+	// Entry is a journaled call.
+	Entry struct {
+		ID        uint64        `json:"id"`
+		Time      time.Time     `json:"time"`
+		Method    string        `json:"method"`
+		Metadata  metadata.MD   `json:"metadata"`
+		Requests  []string      `json:"requests"` // json form of the request messages, as rules see them
+		MatchType string        `json:"match_type"`
+		CaseName  string        `json:"case_name"`
+		Upstream  string        `json:"upstream"` // upstream the call was forwarded to, empty if it was not
+		Code      codes.Code    `json:"code"`
+		Message   string        `json:"message"`
+		Latency   time.Duration `json:"latency"`
+	}
+
+	// Filter selects entries, zero fields select everything.
+	Filter struct {
+		Method   string
+		CaseName string
+		Since    time.Time
+		Until    time.Time
+		Metadata map[string]string // every key must have the given value among its values
+		Limit    int               // only the latest entries
+	}
+
+	// Journal keeps the latest calls the proxy handled.
+	Journal struct {
+		mutex    sync.RWMutex
+		entries  []Entry // ring buffer, next is where the next entry goes
+		next     int
+		full     bool
+		lastID   uint64
+		path     string
+		pending  []Entry       // entries not written to the file yet
+		flush    chan struct{} // wakes up the writer of the file
+		appended int           // entries appended to the file since it was last compacted
+		waiters  map[*waiter]struct{}
+		resolver MethodResolver
+
+		fileMutex sync.Mutex // serializes the writes of the file, taken before mutex
+		file      *os.File
+	}
+)
+
+// NewJournal creates the journal, reloading the latest calls of its file if it has one.
+func NewJournal(c config.JournalConfig, resolver MethodResolver) (*Journal, error) {
+	j := &Journal{
+		waiters:  make(map[*waiter]struct{}),
+		resolver: resolver,
+	}
+	if c.Size > 0 {
+		j.entries = make([]Entry, c.Size)
+	}
+	if c.Path == "" || c.Size <= 0 {
+		return j, nil
+	}
+
+	j.path = c.Path
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := j.rewrite(j.ordered()); err != nil {
+		return nil, err
+	}
+
+	j.flush = make(chan struct{}, 1)
+	threading.GoSafe(j.write)
+
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a line cut short by a crash, the next ones are still good
+			continue
+		}
+		j.put(entry)
+	}
+
+	return scanner.Err()
+}
+
+// Add journals the call and hands it to the waiters it is awaited by.
+func (j *Journal) Add(ctx context.Context, call Call) {
+	if len(j.entries) == 0 && !j.awaited() {
+		return
+	}
+
+	st := status.Convert(call.Err)
+	entry := Entry{
+		Time:      call.Start,
+		Method:    call.FullMethodName,
+		Metadata:  call.MD,
+		Requests:  j.requests(ctx, call),
+		MatchType: call.MatchType,
+		CaseName:  call.CaseName,
+		Code:      st.Code(),
+		Message:   st.Message(),
+		Latency:   time.Since(call.Start),
+	}
+	if call.Forwarded {
+		entry.Upstream, _ = j.resolver.MethodUpstream(ctx, call.FullMethodName)
+	}
+
+	j.mutex.Lock()
+	entry.ID = j.lastID + 1
+	j.lastID = entry.ID
+	if len(j.entries) > 0 {
+		j.put(entry)
+		j.persist(entry)
+	}
+	waiters := make([]*waiter, 0, len(j.waiters))
+	for w := range j.waiters {
+		waiters = append(waiters, w)
+	}
+	j.mutex.Unlock()
+
+	notify(entry, waiters)
+}
+
+// requests decodes the request messages of the call, an undecodable one is left empty.
+func (j *Journal) requests(ctx context.Context, call Call) []string {
+	if len(call.Reqs) == 0 {
+		return nil
+	}
+
+	desc, err := j.resolver.MethodDetail(ctx, call.FullMethodName)
+	if err != nil {
+		return nil
+	}
+
+	requests := make([]string, 0, len(call.Reqs))
+	for _, raw := range call.Reqs {
+		msg := dynamic.NewMessage(desc.In.RawDesc)
+		if err := msg.Unmarshal(raw); err != nil {
+			requests = append(requests, "")
+			continue
+		}
+
+		js, err := msg.MarshalJSONPB(requestMarshaler)
+		if err != nil {
+			requests = append(requests, "")
+			continue
+		}
+		requests = append(requests, string(js))
+	}
+
+	return requests
+}
+
+// put adds the entry to the ring buffer, the caller must hold the lock unless the journal is
+// being created.
+func (j *Journal) put(entry Entry) {
+	j.entries[j.next] = entry
+	j.next = (j.next + 1) % len(j.entries)
+	j.full = j.full || j.next == 0
+	if entry.ID > j.lastID {
+		j.lastID = entry.ID
+	}
+}
+
+// persist queues the entry to be appended to the journal file, the caller must hold the lock.
+func (j *Journal) persist(entry Entry) {
+	if j.flush == nil {
+		return
+	}
+
+	j.pending = append(j.pending, entry)
+	select {
+	case j.flush <- struct{}{}:
+	default:
+	}
+}
+
+// write appends the queued entries to the journal file, off the lock so that neither the calls
+// nor the readers of the journal wait for the file. Once as many entries as the journal keeps
+// were appended, the file is compacted instead.
+func (j *Journal) write() {
+	for range j.flush {
+		j.fileMutex.Lock()
+
+		j.mutex.Lock()
+		pending := j.pending
+		j.pending = nil
+		var kept []Entry
+		compact := j.appended+len(pending) >= len(j.entries)
+		if compact {
+			kept = append(kept, j.ordered()...)
+			j.appended = 0
+		} else {
+			j.appended += len(pending)
+		}
+		j.mutex.Unlock()
+
+		var err error
+		if compact {
+			if err = j.rewrite(kept); err != nil {
+				logx.Errorw("compact journal file error", logx.Field("error", err.Error()))
+			}
+		} else if err = writeEntries(j.file, pending); err != nil {
+			logx.Errorw("write journal file error", logx.Field("error", err.Error()))
+		}
+
+		j.fileMutex.Unlock()
+	}
+}
+
+// writeEntries writes the entries to w as json lines.
+func writeEntries(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		bw.Write(append(line, '\n'))
+	}
+
+	return bw.Flush()
+}
+
+// rewrite replaces the journal file with the given entries, so that it holds at most twice as
+// many as the journal keeps. The caller must hold the file lock unless the journal is being
+// created.
+func (j *Journal) rewrite(entries []Entry) error {
+	tmp := j.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err = writeEntries(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+
+	if j.file != nil {
+		j.file.Close()
+	}
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o644)
+
+	return err
+}
+
+// Reset drops every journaled call.
+func (j *Journal) Reset(ctx context.Context) error {
+	j.fileMutex.Lock()
+	defer j.fileMutex.Unlock()
+
+	j.mutex.Lock()
+	for i := range j.entries {
+		j.entries[i] = Entry{}
+	}
+	j.next = 0
+	j.full = false
+	j.pending = nil
+	j.appended = 0
+	j.mutex.Unlock()
+
+	if j.flush == nil {
+		return nil
+	}
+
+	return j.rewrite(nil)
+}
+
+// Entries returns the journaled calls selected by the filter, oldest first.
+func (j *Journal) Entries(ctx context.Context, filter Filter) []Entry {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	var entries []Entry
+	for _, entry := range j.ordered() {
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+
+	return entries
+}
+
+// ordered returns the entries oldest first, the caller must hold the lock.
+func (j *Journal) ordered() []Entry {
+	if !j.full {
+		return j.entries[:j.next]
+	}
+
+	return append(append([]Entry(nil), j.entries[j.next:]...), j.entries[:j.next]...)
+}
+
+// Match reports whether the filter selects the entry.
+func (f Filter) Match(entry Entry) bool {
+	if f.Method != "" && entry.Method != f.Method {
+		return false
+	}
+	if f.CaseName != "" && entry.CaseName != f.CaseName {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+
+	for key, value := range f.Metadata {
+		var found bool
+		for _, v := range entry.Metadata.Get(key) {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,303 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/journal/config"
+)
+
+const (
+	testMethod = "/test.Echo/Unary"
+	testProto  = `syntax = "proto3";
+package test;
+
+message Req {
+  string id = 1;
+  int64 n = 2;
+  repeated string tags = 3;
+}
+
+message Resp {
+  string msg = 1;
+}
+
+service Echo {
+  rpc Unary(Req) returns (Resp);
+}
+`
+)
+
+type testResolver struct {
+	method parser.MethodDesc
+}
+
+func newTestResolver(t *testing.T) *testResolver {
+	svcs, err := parser.ParseProtos(map[string]string{"test.proto": testProto}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, svcs, 1)
+
+	return &testResolver{method: svcs[0].Methods[0]}
+}
+
+func (r *testResolver) MethodDetail(ctx context.Context, method string) (parser.MethodDesc, error) {
+	if method != r.method.FullName {
+		return parser.MethodDesc{}, errors.New("not found")
+	}
+
+	return r.method, nil
+}
+
+func (r *testResolver) MethodUpstream(ctx context.Context, method string) (string, error) {
+	return "up", nil
+}
+
+// request marshals the json form of a request of the test method.
+func (r *testResolver) request(t *testing.T, js string) []byte {
+	msg := dynamic.NewMessage(r.method.In.RawDesc)
+	require.NoError(t, msg.UnmarshalJSON([]byte(js)))
+	raw, err := msg.Marshal()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func (r *testResolver) call(t *testing.T, js string) Call {
+	return Call{
+		FullMethodName: testMethod,
+		MD:             metadata.Pairs("user", "alice"),
+		Reqs:           [][]byte{r.request(t, js)},
+		MatchType:      "none",
+		Forwarded:      true,
+		Start:          time.Now(),
+	}
+}
+
+func entryIDs(entries []Entry) []uint64 {
+	var ids []uint64
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+
+	return ids
+}
+
+func TestJournal_Add(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	j.Add(ctx, r.call(t, `{"id":"a","n":"1","tags":["x"]}`))
+	call := r.call(t, `{"id":"b"}`)
+	call.MatchType = "body"
+	call.CaseName = "b"
+	call.Forwarded = false
+	call.Err = status.Error(codes.NotFound, "no b")
+	j.Add(ctx, call)
+
+	entries := j.Entries(ctx, Filter{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, []uint64{1, 2}, entryIDs(entries))
+
+	assert.Equal(t, testMethod, entries[0].Method)
+	assert.Equal(t, []string{`{"id":"a","n":"1","tags":["x"]}`}, entries[0].Requests)
+	assert.Equal(t, "up", entries[0].Upstream)
+	assert.Equal(t, codes.OK, entries[0].Code)
+	assert.Equal(t, []string{"alice"}, entries[0].Metadata.Get("user"))
+
+	// defaults are emitted, as rules see them
+	assert.Equal(t, []string{`{"id":"b","n":"0","tags":[]}`}, entries[1].Requests)
+	assert.Equal(t, "body", entries[1].MatchType)
+	assert.Equal(t, "b", entries[1].CaseName)
+	assert.Empty(t, entries[1].Upstream)
+	assert.Equal(t, codes.NotFound, entries[1].Code)
+	assert.Equal(t, "no b", entries[1].Message)
+}
+
+func TestJournal_AddUndecodable(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	call := r.call(t, `{"id":"a"}`)
+	call.Reqs = append(call.Reqs, []byte{0xff})
+	j.Add(ctx, call)
+	call.FullMethodName = "/test.Echo/Unknown"
+	j.Add(ctx, call)
+
+	entries := j.Entries(ctx, Filter{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{`{"id":"a","n":"0","tags":[]}`, ""}, entries[0].Requests)
+	assert.Empty(t, entries[1].Requests)
+}
+
+func TestJournal_RingBuffer(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 3}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		j.Add(ctx, r.call(t, `{}`))
+	}
+	assert.Equal(t, []uint64{1, 2}, entryIDs(j.Entries(ctx, Filter{})))
+
+	j.Add(ctx, r.call(t, `{}`))
+	assert.Equal(t, []uint64{1, 2, 3}, entryIDs(j.Entries(ctx, Filter{})))
+
+	for i := 0; i < 4; i++ {
+		j.Add(ctx, r.call(t, `{}`))
+	}
+	assert.Equal(t, []uint64{5, 6, 7}, entryIDs(j.Entries(ctx, Filter{})))
+	assert.Equal(t, []uint64{6, 7}, entryIDs(j.Entries(ctx, Filter{Limit: 2})))
+}
+
+func TestJournal_Disabled(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 0, Path: filepath.Join(t.TempDir(), "calls.jsonl")}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	j.Add(ctx, r.call(t, `{}`))
+	assert.Empty(t, j.Entries(ctx, Filter{}))
+	assert.NoError(t, j.Reset(ctx))
+}
+
+func TestJournal_Entries(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	start := time.Now()
+	for i, name := range []string{"a", "b", "a"} {
+		call := r.call(t, `{}`)
+		call.CaseName = name
+		call.Start = start.Add(time.Duration(i) * time.Second)
+		call.MD = metadata.Pairs("user", name, "tenant", "t")
+		j.Add(ctx, call)
+	}
+	other := r.call(t, `{}`)
+	other.FullMethodName = "/test.Echo/Other"
+	other.Start = start
+	j.Add(ctx, other)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []uint64
+	}{
+		{name: "all", filter: Filter{}, want: []uint64{1, 2, 3, 4}},
+		{name: "method", filter: Filter{Method: testMethod}, want: []uint64{1, 2, 3}},
+		{name: "case", filter: Filter{CaseName: "a"}, want: []uint64{1, 3}},
+		{name: "since", filter: Filter{Since: start.Add(time.Second)}, want: []uint64{2, 3}},
+		{name: "until", filter: Filter{Until: start.Add(time.Second)}, want: []uint64{1, 2, 4}},
+		{name: "metadata", filter: Filter{Metadata: map[string]string{"user": "b"}}, want: []uint64{2}},
+		{
+			name:   "every metadata key",
+			filter: Filter{Metadata: map[string]string{"user": "a", "tenant": "t"}},
+			want:   []uint64{1, 3},
+		},
+		{name: "metadata mismatch", filter: Filter{Metadata: map[string]string{"tenant": "u"}}},
+		{name: "limit", filter: Filter{Method: testMethod, Limit: 1}, want: []uint64{3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, entryIDs(j.Entries(ctx, test.filter)))
+		})
+	}
+}
+
+// fileIDs returns the ids of the entries in the journal file.
+func fileIDs(path string) []uint64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var ids []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			ids = append(ids, entry.ID)
+		}
+	}
+
+	return ids
+}
+
+// written waits until the last entry of the journal file is the given one.
+func written(t *testing.T, path string, id uint64) []uint64 {
+	var ids []uint64
+	assert.Eventually(t, func() bool {
+		ids = fileIDs(path)
+		return len(ids) > 0 && ids[len(ids)-1] == id
+	}, time.Second, 5*time.Millisecond)
+
+	return ids
+}
+
+func TestJournal_Persist(t *testing.T) {
+	r := newTestResolver(t)
+	path := filepath.Join(t.TempDir(), "journal", "calls.jsonl")
+	j, err := NewJournal(config.JournalConfig{Size: 3, Path: path}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	j.Add(ctx, r.call(t, `{"id":"a"}`))
+	j.Add(ctx, r.call(t, `{"id":"b"}`))
+	assert.Equal(t, []uint64{1, 2}, written(t, path, 2))
+
+	// appending as many entries as the journal keeps compacts the file to them
+	for i := 0; i < 5; i++ {
+		j.Add(ctx, r.call(t, `{"id":"c"}`))
+	}
+	ids := written(t, path, 7)
+	assert.LessOrEqual(t, len(ids), 2*3)
+	assert.Subset(t, ids, []uint64{5, 6, 7})
+
+	reloaded, err := NewJournal(config.JournalConfig{Size: 2, Path: path}, r)
+	require.NoError(t, err)
+	entries := reloaded.Entries(ctx, Filter{})
+	assert.Equal(t, []uint64{6, 7}, entryIDs(entries))
+	assert.Equal(t, []string{`{"id":"c","n":"0","tags":[]}`}, entries[1].Requests)
+	// the file was compacted on reload
+	assert.Equal(t, []uint64{6, 7}, fileIDs(path))
+
+	// ids go on from the reloaded ones
+	reloaded.Add(ctx, r.call(t, `{}`))
+	assert.Equal(t, []uint64{7, 8}, entryIDs(reloaded.Entries(ctx, Filter{})))
+	written(t, path, 8)
+}
+
+func TestJournal_LoadSkipsBrokenLines(t *testing.T) {
+	r := newTestResolver(t)
+	path := filepath.Join(t.TempDir(), "calls.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":1,"method":"/test.Echo/Unary"}
+{"id":2,"meth
+{"id":3,"method":"/test.Echo/Unary"}
+`), 0o644))
+
+	j, err := NewJournal(config.JournalConfig{Size: 10, Path: path}, r)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 3}, entryIDs(j.Entries(context.Background(), Filter{})))
+}
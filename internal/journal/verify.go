@@ -0,0 +1,90 @@
+package journal
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+// maxNearMisses is the number of calls the rule did not hold for a verification reports.
+const maxNearMisses = 5
+
+type (
+	// Verification is the outcome of checking the journaled calls against a rule.
+	Verification struct {
+		Total      int // calls selected by the filter
+		Matches    []Entry
+		NearMisses []NearMiss // the calls nearest to matching first
+	}
+
+	// NearMiss is a call the rule did not hold for, with how many of its conditions held.
+	NearMiss struct {
+		Entry  Entry
+		Held   int
+		Failed []string
+	}
+)
+
+// Verify checks the calls selected by the filter against the rule, evaluated on their requests as
+// case rules are, an empty rule holds for every call.
+func (j *Journal) Verify(ctx context.Context, filter Filter, source string) (Verification, error) {
+	var verification Verification
+	if source != "" {
+		if _, err := rule.Compile(source); err != nil {
+			return verification, err
+		}
+	}
+
+	entries := j.Entries(ctx, filter)
+	verification.Total = len(entries)
+	for _, entry := range entries {
+		if source == "" {
+			verification.Matches = append(verification.Matches, entry)
+			continue
+		}
+
+		env := entryEnv(entry)
+		if rule.Holds(source, env) {
+			verification.Matches = append(verification.Matches, entry)
+			continue
+		}
+
+		held, failed := rule.Explain(source, env)
+		verification.NearMisses = append(verification.NearMisses, NearMiss{
+			Entry:  entry,
+			Held:   held,
+			Failed: failed,
+		})
+	}
+
+	sort.SliceStable(verification.NearMisses, func(i, k int) bool {
+		a, b := verification.NearMisses[i], verification.NearMisses[k]
+		return a.Held*(b.Held+len(b.Failed)) > b.Held*(a.Held+len(a.Failed))
+	})
+	if len(verification.NearMisses) > maxNearMisses {
+		verification.NearMisses = verification.NearMisses[:maxNearMisses]
+	}
+
+	return verification, nil
+}
+
+// entryEnv builds the rule environment of the journaled call: json queries its first request and
+// messages holds all of them.
+func entryEnv(entry Entry) map[string]interface{} {
+	var first string
+	if len(entry.Requests) > 0 {
+		first = entry.Requests[0]
+	}
+
+	messages := make([]interface{}, 0, len(entry.Requests))
+	for _, req := range entry.Requests {
+		messages = append(messages, gjson.Parse(req).Value())
+	}
+
+	return rule.Env(func(path string) interface{} {
+		return gjson.Get(first, path).Value()
+	}, messages)
+}
@@ -0,0 +1,139 @@
+package journal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeromicro/grpc-mock/internal/journal/config"
+)
+
+func TestJournal_Verify(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, req := range []string{
+		`{"id":"a","n":"1"}`,
+		`{"id":"a","n":"2","tags":["x"]}`,
+		`{"id":"b","n":"2"}`,
+		`{"id":"c","n":"3"}`,
+	} {
+		j.Add(ctx, r.call(t, req))
+	}
+
+	tests := []struct {
+		name       string
+		rule       string
+		matches    []uint64
+		nearMisses []uint64
+		held       []int
+	}{
+		{name: "empty rule", matches: []uint64{1, 2, 3, 4}},
+		{name: "no match", rule: `json("id") == "z"`, nearMisses: []uint64{1, 2, 3, 4}, held: []int{0, 0, 0, 0}},
+		{
+			name:       "conjunction",
+			rule:       `json("id") == "a" && json("n") == "2" && len(json("tags")) == 1`,
+			matches:    []uint64{2},
+			nearMisses: []uint64{1, 3, 4},
+			held:       []int{1, 1, 0},
+		},
+		{
+			name:       "messages",
+			rule:       `len(messages) == 1 && messages[0]["id"] == "b"`,
+			matches:    []uint64{3},
+			nearMisses: []uint64{1, 2, 4},
+			held:       []int{1, 1, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verification, err := j.Verify(ctx, Filter{Method: testMethod}, test.rule)
+			require.NoError(t, err)
+
+			assert.Equal(t, 4, verification.Total)
+			assert.Equal(t, test.matches, entryIDs(verification.Matches))
+
+			var nearMisses []uint64
+			var held []int
+			for _, miss := range verification.NearMisses {
+				nearMisses = append(nearMisses, miss.Entry.ID)
+				held = append(held, miss.Held)
+			}
+			assert.Equal(t, test.nearMisses, nearMisses)
+			assert.Equal(t, test.held, held)
+		})
+	}
+}
+
+func TestJournal_VerifyFailedConditions(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	j.Add(ctx, r.call(t, `{"id":"a","n":"1"}`))
+
+	verification, err := j.Verify(ctx, Filter{}, `json("id") == "a" && json("n") == "2"`)
+	require.NoError(t, err)
+	require.Len(t, verification.NearMisses, 1)
+	assert.Equal(t, 1, verification.NearMisses[0].Held)
+	assert.Equal(t, []string{`json("n") == "2"`}, verification.NearMisses[0].Failed)
+}
+
+func TestJournal_VerifyLimitsNearMisses(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 20}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < maxNearMisses+3; i++ {
+		j.Add(ctx, r.call(t, `{"id":"a"}`))
+	}
+
+	verification, err := j.Verify(ctx, Filter{}, `json("id") == "b"`)
+	require.NoError(t, err)
+	assert.Equal(t, maxNearMisses+3, verification.Total)
+	assert.Empty(t, verification.Matches)
+	assert.Len(t, verification.NearMisses, maxNearMisses)
+}
+
+func TestJournal_VerifyInvalidRule(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	_, err = j.Verify(context.Background(), Filter{}, `json("id") ==`)
+	assert.Error(t, err)
+}
+
+func TestJournal_Reset(t *testing.T) {
+	r := newTestResolver(t)
+	path := filepath.Join(t.TempDir(), "calls.jsonl")
+	j, err := NewJournal(config.JournalConfig{Size: 3, Path: path}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		j.Add(ctx, r.call(t, `{}`))
+	}
+	written(t, path, 4)
+
+	require.NoError(t, j.Reset(ctx))
+	assert.Empty(t, j.Entries(ctx, Filter{}))
+	assert.Empty(t, fileIDs(path))
+
+	// ids are not reused after a reset
+	j.Add(ctx, r.call(t, `{}`))
+	assert.Equal(t, []uint64{5}, entryIDs(j.Entries(ctx, Filter{})))
+	assert.Equal(t, []uint64{5}, written(t, path, 5))
+
+	reloaded, err := NewJournal(config.JournalConfig{Size: 3, Path: path}, r)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{5}, entryIDs(reloaded.Entries(ctx, Filter{})))
+}
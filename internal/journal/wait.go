@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"context"
+
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+// waiter is a call to Wait, found receives the first call it awaits.
+type waiter struct {
+	filter Filter
+	rule   string
+	found  chan Entry
+}
+
+// Wait returns the first call selected by the filter the rule holds for, an empty rule holds for
+// every call. If the filter has a start time the calls journaled since then are looked at first,
+// then the ones to come until ctx is done, in which case it returns false.
+func (j *Journal) Wait(ctx context.Context, filter Filter, source string) (Entry, bool, error) {
+	if source != "" {
+		if _, err := rule.Compile(source); err != nil {
+			return Entry{}, false, err
+		}
+	}
+
+	w := &waiter{
+		filter: filter,
+		rule:   source,
+		found:  make(chan Entry, 1),
+	}
+
+	// registered along with the lookup of the past calls, so that none falls between the two
+	j.mutex.Lock()
+	j.waiters[w] = struct{}{}
+	var past []Entry
+	if !filter.Since.IsZero() {
+		for _, entry := range j.ordered() {
+			if filter.Match(entry) {
+				past = append(past, entry)
+			}
+		}
+	}
+	j.mutex.Unlock()
+
+	defer func() {
+		j.mutex.Lock()
+		delete(j.waiters, w)
+		j.mutex.Unlock()
+	}()
+
+	for _, entry := range past {
+		if w.holds(entry, nil) {
+			return entry, true, nil
+		}
+	}
+
+	select {
+	case entry := <-w.found:
+		return entry, true, nil
+	case <-ctx.Done():
+		return Entry{}, false, nil
+	}
+}
+
+// awaited reports whether calls are waited for, so that they are decoded even when the journal
+// keeps none.
+func (j *Journal) awaited() bool {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	return len(j.waiters) > 0
+}
+
+// notify hands the entry to the waiters awaiting it, the ones that already got a call keep it.
+func notify(entry Entry, waiters []*waiter) {
+	var env map[string]interface{}
+	for _, w := range waiters {
+		if !w.filter.Match(entry) {
+			continue
+		}
+		if w.rule != "" && env == nil {
+			env = entryEnv(entry)
+		}
+		if !w.holds(entry, env) {
+			continue
+		}
+
+		select {
+		case w.found <- entry:
+		default:
+		}
+	}
+}
+
+// holds reports whether the rule of the waiter holds for the entry, env is its rule environment
+// if already built.
+func (w *waiter) holds(entry Entry, env map[string]interface{}) bool {
+	if w.rule == "" {
+		return true
+	}
+	if env == nil {
+		env = entryEnv(entry)
+	}
+
+	return rule.Holds(w.rule, env)
+}
@@ -0,0 +1,190 @@
+package journal
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zeromicro/grpc-mock/internal/journal/config"
+)
+
+// waitResult is the outcome of a Wait run in the background.
+type waitResult struct {
+	entry Entry
+	found bool
+	err   error
+}
+
+func waitAsync(ctx context.Context, j *Journal, filter Filter, rule string) <-chan waitResult {
+	results := make(chan waitResult, 1)
+	go func() {
+		entry, found, err := j.Wait(ctx, filter, rule)
+		results <- waitResult{entry: entry, found: found, err: err}
+	}()
+
+	return results
+}
+
+// waiting blocks until the journal has n waiters.
+func waiting(t *testing.T, j *Journal, n int) {
+	require.Eventually(t, func() bool {
+		j.mutex.RLock()
+		defer j.mutex.RUnlock()
+		return len(j.waiters) == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestJournal_WaitFuture(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results := waitAsync(ctx, j, Filter{Method: testMethod}, `json("id") == "b"`)
+	waiting(t, j, 1)
+
+	j.Add(ctx, r.call(t, `{"id":"a"}`))
+	other := r.call(t, `{"id":"b"}`)
+	other.FullMethodName = "/test.Echo/Other"
+	j.Add(ctx, other)
+	j.Add(ctx, r.call(t, `{"id":"b","n":"1"}`))
+	j.Add(ctx, r.call(t, `{"id":"b","n":"2"}`))
+
+	result := <-results
+	require.NoError(t, result.err)
+	assert.True(t, result.found)
+	assert.Equal(t, uint64(3), result.entry.ID)
+	assert.Equal(t, []string{`{"id":"b","n":"1","tags":[]}`}, result.entry.Requests)
+	waiting(t, j, 0)
+}
+
+func TestJournal_WaitPast(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	since := time.Now()
+	early := r.call(t, `{"id":"a"}`)
+	early.Start = since.Add(-time.Second)
+	j.Add(ctx, early)
+	j.Add(ctx, r.call(t, `{"id":"a","n":"1"}`))
+	j.Add(ctx, r.call(t, `{"id":"a","n":"2"}`))
+
+	entry, found, err := j.Wait(ctx, Filter{Method: testMethod, Since: since}, `json("id") == "a"`)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), entry.ID)
+
+	// without a start time only the calls to come are awaited
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, found, err = j.Wait(timeout, Filter{Method: testMethod}, `json("id") == "a"`)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestJournal_WaitTimeout(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	results := waitAsync(ctx, j, Filter{Method: testMethod}, `json("id") == "b"`)
+	waiting(t, j, 1)
+	j.Add(context.Background(), r.call(t, `{"id":"a"}`))
+
+	result := <-results
+	require.NoError(t, result.err)
+	assert.False(t, result.found)
+	waiting(t, j, 0)
+}
+
+func TestJournal_WaitInvalidRule(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	_, _, err = j.Wait(context.Background(), Filter{}, `json("id") ==`)
+	assert.Error(t, err)
+	waiting(t, j, 0)
+}
+
+func TestJournal_WaitDisabled(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 0}, r)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results := waitAsync(ctx, j, Filter{Method: testMethod}, "")
+	waiting(t, j, 1)
+	j.Add(ctx, r.call(t, `{"id":"a"}`))
+
+	result := <-results
+	require.NoError(t, result.err)
+	assert.True(t, result.found)
+	assert.Equal(t, []string{`{"id":"a","n":"0","tags":[]}`}, result.entry.Requests)
+	assert.Empty(t, j.Entries(ctx, Filter{}))
+}
+
+func TestJournal_WaitMany(t *testing.T) {
+	r := newTestResolver(t)
+	j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := waitAsync(ctx, j, Filter{}, `json("id") == "a"`)
+	b := waitAsync(ctx, j, Filter{}, `json("id") == "b"`)
+	every := waitAsync(ctx, j, Filter{}, "")
+	waiting(t, j, 3)
+
+	j.Add(ctx, r.call(t, `{"id":"b"}`))
+	j.Add(ctx, r.call(t, `{"id":"a"}`))
+
+	assert.Equal(t, uint64(2), (<-a).entry.ID)
+	assert.Equal(t, uint64(1), (<-b).entry.ID)
+	assert.Equal(t, uint64(1), (<-every).entry.ID)
+}
+
+// TestJournal_WaitRace checks that a call journaled while the wait starts is found, whether it
+// lands before the waiter is registered or after.
+func TestJournal_WaitRace(t *testing.T) {
+	r := newTestResolver(t)
+	ctx := context.Background()
+	call := r.call(t, `{"id":"a"}`)
+
+	for i := 0; i < 200; i++ {
+		j, err := NewJournal(config.JournalConfig{Size: 10}, r)
+		require.NoError(t, err)
+
+		since := time.Now()
+		call.Start = time.Now()
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.Add(ctx, call)
+		}()
+
+		timeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, found, err := j.Wait(timeout, Filter{Method: testMethod, Since: since}, `json("id") == "a"`)
+		cancel()
+		wg.Wait()
+
+		require.NoError(t, err)
+		require.True(t, found, "iteration %d", i)
+	}
+}
@@ -1,8 +1,50 @@
 package config
 
-type MatchConfig struct {
-	MockEnableKey     string `json:",default=mock"`
-	MockEnableValue   string `json:",default=yes"`
-	MockCaseKey       string `json:",default=case_name"`
-	MockCustomCaseKey string `json:",default=custom_case"`
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+type (
+	MatchConfig struct {
+		MockEnableKey     string `json:",default=mock"`
+		MockEnableValue   string `json:",default=yes"`
+		MockCaseKey       string `json:",default=case_name"`
+		MockCustomCaseKey string `json:",default=custom_case"`
+		// Fake answers calls that match no case with generated responses when their upstream is
+		// unavailable or disabled, instead of failing them.
+		Fake bool `json:",default=false"`
+		// Strict fails the calls that match no case instead of forwarding them to their upstream.
+		Strict StrictConfig
+	}
+
+	StrictConfig struct {
+		Enabled bool     `json:",default=false"`         // for every method
+		Methods []string `json:",optional"`              // full names of the methods it is enabled for, when not for every one
+		Code    string   `json:",default=Unimplemented"` // gRPC code the calls fail with, e.g. NotFound or NOT_FOUND
+		Message string   `json:",optional"`              // replaces the default message, hints about the nearest cases are still appended
+	}
+)
+
+// StatusCode parses the gRPC code calls fail with, given by name or number. OK is rejected, a call
+// failed with it would be forwarded anyway.
+func (c StrictConfig) StatusCode() (codes.Code, error) {
+	if n, err := strconv.ParseUint(c.Code, 10, 32); err == nil && n > 0 && n <= uint64(codes.Unauthenticated) {
+		return codes.Code(n), nil
+	}
+
+	name := strings.ReplaceAll(c.Code, "_", "")
+	if strings.EqualFold(name, codes.OK.String()) || c.Code == "0" {
+		return codes.Unknown, fmt.Errorf("strict: gRPC code %q is not an error code", c.Code)
+	}
+	for code := codes.Canceled; code <= codes.Unauthenticated; code++ {
+		if strings.EqualFold(code.String(), name) {
+			return code, nil
+		}
+	}
+
+	return codes.Unknown, fmt.Errorf("strict: unknown gRPC code %q", c.Code)
 }
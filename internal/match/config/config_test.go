@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestStrictConfig_StatusCode(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    codes.Code
+		wantErr bool
+	}{
+		{code: "Unimplemented", want: codes.Unimplemented},
+		{code: "NotFound", want: codes.NotFound},
+		{code: "NOT_FOUND", want: codes.NotFound},
+		{code: "failed_precondition", want: codes.FailedPrecondition},
+		{code: "Canceled", want: codes.Canceled},
+		{code: "5", want: codes.NotFound},
+		{code: "1", want: codes.Canceled},
+		{code: "16", want: codes.Unauthenticated},
+		{code: "OK", wantErr: true},
+		{code: "ok", wantErr: true},
+		{code: "0", wantErr: true},
+		{code: "17", wantErr: true},
+		{code: "-1", wantErr: true},
+		{code: "Nope", wantErr: true},
+		{code: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			code, err := StrictConfig{Code: test.code}.StatusCode()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, code)
+		})
+	}
+}
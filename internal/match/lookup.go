@@ -0,0 +1,229 @@
+package match
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/tidwall/gjson"
+)
+
+// jsonMarshaler gives the json form rules query, with proto field names and every field set.
+var jsonMarshaler = &jsonpb.Marshaler{OrigName: true, EnumsAsInts: true, EmitDefaults: true}
+
+// messageQuery answers the json queries of rules on a request message. Plain paths are looked up
+// on the message fields directly, the json form is only built, once, for paths using gjson
+// modifiers, wildcards or queries, and for well-known types which have their own json form.
+type messageQuery struct {
+	msg *dynamic.Message
+	js  []byte
+}
+
+func (q *messageQuery) get(path string) interface{} {
+	if v, ok := lookup(q.msg, path); ok {
+		return v
+	}
+
+	if q.js == nil {
+		js, err := q.msg.MarshalJSONPB(jsonMarshaler)
+		if err != nil {
+			return nil
+		}
+		q.js = js
+	}
+
+	return gjson.GetBytes(q.js, path).Value()
+}
+
+// lookup returns the value of the gjson path in the json form of msg without building it, ok is
+// false if the path cannot be looked up that way.
+func lookup(msg *dynamic.Message, path string) (v interface{}, ok bool) {
+	if path == "" || strings.ContainsAny(path, `\*?|@[]{}()!=<>%"`) {
+		return nil, false
+	}
+
+	segs := strings.Split(path, ".")
+	for i := 0; i < len(segs); i++ {
+		fd := msg.GetMessageDescriptor().FindFieldByName(segs[i])
+		if fd == nil || !present(msg, fd) {
+			return nil, true
+		}
+
+		value := msg.GetField(fd)
+		if i == len(segs)-1 {
+			return fieldValue(fd, value), true
+		}
+
+		switch {
+		case fd.IsMap():
+			i++
+			value, ok = mapEntry(value.(map[interface{}]interface{}), segs[i])
+			if !ok {
+				return nil, true
+			}
+			if i == len(segs)-1 {
+				return singleValue(value), true
+			}
+			fd = fd.GetMapValueType()
+		case fd.IsRepeated():
+			i++
+			list := value.([]interface{})
+			if segs[i] == "#" {
+				if i == len(segs)-1 {
+					return float64(len(list)), true
+				}
+				return nil, false
+			}
+			index, err := strconv.Atoi(segs[i])
+			if err != nil || index < 0 || index >= len(list) {
+				return nil, true
+			}
+			if i == len(segs)-1 {
+				return singleValue(list[index]), true
+			}
+			value = list[index]
+		}
+
+		if md := fd.GetMessageType(); md != nil && isWellKnown(md) {
+			return nil, false
+		}
+		next, ok := value.(*dynamic.Message)
+		if !ok {
+			// a scalar or an unset message has no fields
+			return nil, true
+		}
+		msg = next
+	}
+
+	return nil, true
+}
+
+// present reports whether the field has a value in the json form: unset oneof fields are left out
+// and unset messages are null, every other field is printed with its default value.
+func present(msg *dynamic.Message, fd *desc.FieldDescriptor) bool {
+	if fd.GetOneOf() != nil || !fd.IsRepeated() && fd.GetMessageType() != nil {
+		return msg.HasField(fd)
+	}
+
+	return true
+}
+
+func mapEntry(entries map[interface{}]interface{}, key string) (interface{}, bool) {
+	for k, v := range entries {
+		if fmt.Sprint(k) == key {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// messageValue returns the json form of msg as gjson would decode it, without building it.
+func messageValue(msg *dynamic.Message) interface{} {
+	if isWellKnown(msg.GetMessageDescriptor()) {
+		return jsonValue(msg)
+	}
+
+	obj := make(map[string]interface{})
+	for _, fd := range msg.GetMessageDescriptor().GetFields() {
+		if !present(msg, fd) {
+			if fd.GetOneOf() == nil {
+				obj[fd.GetName()] = nil
+			}
+			continue
+		}
+		obj[fd.GetName()] = fieldValue(fd, msg.GetField(fd))
+	}
+
+	return obj
+}
+
+func fieldValue(fd *desc.FieldDescriptor, value interface{}) interface{} {
+	switch {
+	case fd.IsMap():
+		entries := value.(map[interface{}]interface{})
+		obj := make(map[string]interface{}, len(entries))
+		for k, v := range entries {
+			obj[fmt.Sprint(k)] = singleValue(v)
+		}
+		return obj
+	case fd.IsRepeated():
+		list := value.([]interface{})
+		arr := make([]interface{}, 0, len(list))
+		for _, v := range list {
+			arr = append(arr, singleValue(v))
+		}
+		return arr
+	default:
+		return singleValue(value)
+	}
+}
+
+// singleValue converts a single field value, an element for repeated fields, the way jsonpb
+// prints it and gjson reads it back: 64-bit integers are quoted, other numbers are float64.
+func singleValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *dynamic.Message:
+		return messageValue(v)
+	case int32:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		if special := specialFloat(float64(v)); special != "" {
+			return special
+		}
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+		return f
+	case float64:
+		if special := specialFloat(v); special != "" {
+			return special
+		}
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case proto.Message:
+		// well-known types are decoded into their generated message
+		return jsonValue(v)
+	}
+
+	return value
+}
+
+func specialFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return ""
+	}
+}
+
+func jsonValue(msg proto.Message) interface{} {
+	js, err := jsonMarshaler.MarshalToString(msg)
+	if err != nil {
+		return nil
+	}
+
+	return gjson.Parse(js).Value()
+}
+
+func isWellKnown(md *desc.MessageDescriptor) bool {
+	return strings.HasPrefix(md.GetFullyQualifiedName(), "google.protobuf.")
+}
@@ -0,0 +1,169 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+)
+
+const testProto = `syntax = "proto3";
+package test;
+
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/wrappers.proto";
+import "google/protobuf/struct.proto";
+
+enum Kind {
+  KIND_UNKNOWN = 0;
+  KIND_A = 1;
+}
+
+message Leaf {
+  string name = 1;
+  int32 count = 2;
+  repeated string labels = 3;
+}
+
+message Inner {
+  string name = 1;
+  int32 count = 2;
+  Leaf child = 3;
+  repeated string labels = 4;
+}
+
+message Req {
+  string id = 1;
+  int32 i32 = 2;
+  int64 i64 = 3;
+  uint32 u32 = 4;
+  uint64 u64 = 5;
+  float f32 = 6;
+  double f64 = 7;
+  bool flag = 8;
+  bytes data = 9;
+  Kind kind = 10;
+  Inner inner = 11;
+  repeated Inner items = 12;
+  repeated int64 ids = 13;
+  map<string, Inner> by_name = 14;
+  map<int32, string> by_num = 15;
+  oneof choice {
+    string text = 16;
+    Inner nested = 17;
+  }
+  google.protobuf.Timestamp at = 18;
+  google.protobuf.StringValue note = 19;
+  google.protobuf.Struct extra = 20;
+  repeated google.protobuf.Timestamp times = 21;
+  map<string, google.protobuf.Int64Value> counters = 22;
+}
+
+message Resp {}
+
+service Test {
+  rpc Call(Req) returns (Resp);
+}
+`
+
+func testMethod(t testing.TB) parser.MethodDesc {
+	svcs, err := parser.ParseProtos(map[string]string{"test.proto": testProto}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, svcs, 1)
+
+	return svcs[0].Methods[0]
+}
+
+func testMessage(t testing.TB, desc parser.MethodDesc, js string) *dynamic.Message {
+	msg := dynamic.NewMessage(desc.In.RawDesc)
+	require.NoError(t, msg.UnmarshalJSON([]byte(js)))
+
+	return msg
+}
+
+// TestLookup checks that the rules read the same values whether a path is looked up on the
+// message or queried with gjson on its json form.
+func TestLookup(t *testing.T) {
+	desc := testMethod(t)
+
+	messages := map[string]string{
+		"empty": `{}`,
+		"scalars": `{"id":"a.b","i32":-3,"i64":"-9007199254740993","u32":7,"u64":"18446744073709551615",
+			"f32":0.1,"f64":2.5,"flag":true,"data":"aGk=","kind":"KIND_A"}`,
+		"special floats": `{"f32":"NaN","f64":"-Infinity"}`,
+		"nested":         `{"inner":{"name":"in","count":2,"child":{"name":"deep","labels":["x"]}}}`,
+		"repeated":       `{"items":[{"name":"a"},{"name":"b","child":{"count":1}}],"ids":["1","2","3"]}`,
+		"maps":           `{"by_name":{"k":{"name":"v","labels":["l"]},"dot":{"count":3}},"by_num":{"1":"one","-2":"minus two"}}`,
+		"oneof text":     `{"text":"t"}`,
+		"oneof nested":   `{"nested":{"name":"n"}}`,
+		"well-known": `{"at":"2024-01-02T03:04:05.000000006Z","note":"hi","extra":{"a":[1,"b",null],"c":{"d":true}},
+			"times":["2024-01-02T03:04:05Z"],"counters":{"c":"12"}}`,
+	}
+
+	paths := []string{
+		"id", "i32", "i64", "u32", "u64", "f32", "f64", "flag", "data", "kind",
+		"inner", "inner.name", "inner.count", "inner.child", "inner.child.name", "inner.child.labels",
+		"inner.child.labels.0", "inner.child.labels.#", "inner.missing", "inner.name.more",
+		"items", "items.#", "items.0", "items.1.name", "items.1.child.count", "items.2", "items.-1", "items.x",
+		"items.#.name", "ids", "ids.1", "ids.#",
+		"by_name", "by_name.k", "by_name.k.name", "by_name.k.labels.0", "by_name.dot.count", "by_name.none",
+		"by_num", "by_num.1", "by_num.-2", "by_num.3",
+		"text", "nested", "nested.name", "choice",
+		"at", "at.seconds", "note", "extra", "extra.a.1", "extra.c.d", "times", "times.0", "counters", "counters.c",
+		"unknown", "", "items|@reverse", `by_name.k\.x`, "id*", "items.#(name==\"b\").name",
+	}
+
+	for name, js := range messages {
+		t.Run(name, func(t *testing.T) {
+			msg := testMessage(t, desc, js)
+			form, err := msg.MarshalJSONPB(jsonMarshaler)
+			require.NoError(t, err)
+
+			for _, path := range paths {
+				query := &messageQuery{msg: msg}
+				assert.Equal(t, gjson.GetBytes(form, path).Value(), query.get(path), "path %q of %s", path, form)
+			}
+
+			assert.Equal(t, gjson.ParseBytes(form).Value(), messageValue(msg), "message value of %s", form)
+		})
+	}
+}
+
+// TestLookupDirect checks which paths are looked up on the message, the other ones are queried
+// on its json form.
+func TestLookupDirect(t *testing.T) {
+	desc := testMethod(t)
+	msg := testMessage(t, desc, `{"items":[{"name":"a"}],"inner":{"name":"in"},"at":"2024-01-02T03:04:05Z",
+		"times":["2024-01-02T03:04:05Z"]}`)
+
+	tests := []struct {
+		path   string
+		direct bool
+	}{
+		{path: "id", direct: true},
+		{path: "inner.name", direct: true},
+		{path: "items.0.name", direct: true},
+		{path: "items.#", direct: true},
+		{path: "by_name.k", direct: true},
+		{path: "unknown", direct: true},
+		{path: "at", direct: true},
+		{path: "times.0", direct: true},
+		{path: "at.seconds"},
+		{path: "times.0.seconds"},
+		{path: "items.#.name"},
+		{path: "items|@reverse"},
+		{path: "it*"},
+		{path: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			_, ok := lookup(msg, test.path)
+			assert.Equal(t, test.direct, ok)
+		})
+	}
+}
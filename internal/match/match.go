@@ -3,26 +3,45 @@ package match
 import (
 	"bytes"
 	"context"
+	"math/rand"
+	"time"
 
 	"github.com/antonmedv/expr"
 	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 	"github.com/jhump/protoreflect/dynamic"
-	"github.com/tidwall/gjson"
 	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
 	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
 	"github.com/zeromicro/grpc-mock/internal/svc"
 )
 
 type Matcher struct {
-	svcCtx *svc.ServiceContext
+	svcCtx        *svc.ServiceContext
+	strictCode    codes.Code
+	strictMethods map[string]struct{}
 }
 
 func NewMatcher(svcCtx *svc.ServiceContext) *Matcher {
+	strictCode, err := svcCtx.Config.MatchConf.Strict.StatusCode()
+	logx.Must(err)
+
+	strictMethods := make(map[string]struct{})
+	for _, method := range svcCtx.Config.MatchConf.Strict.Methods {
+		strictMethods[method] = struct{}{}
+	}
+
 	return &Matcher{
-		svcCtx: svcCtx,
+		svcCtx:        svcCtx,
+		strictCode:    strictCode,
+		strictMethods: strictMethods,
 	}
 }
 
@@ -36,7 +55,46 @@ func (m *Matcher) Match(ctx context.Context, req Request) (*Response, error) {
 		return resp, nil
 	}
 	// 2. match with request body
-	return m.matchWithRequestBody(ctx, req)
+	resp, err = m.matchWithRequestBody(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.MatchType != MatchedTypeNone {
+		return resp, nil
+	}
+	// 3. fake a response if the call cannot be forwarded
+	return m.fake(ctx, req)
+}
+
+// fake generates a response from the method schema when fake mode is on and the upstream of the
+// method is unavailable or disabled, server streaming methods get a few generated messages.
+func (m *Matcher) fake(ctx context.Context, req Request) (*Response, error) {
+	if !m.svcCtx.Config.MatchConf.Fake || m.svcCtx.DialManager.Available(ctx, req.FullMethodName) {
+		return &Response{
+			MatchType: MatchedTypeNone,
+		}, nil
+	}
+
+	desc, err := m.svcCtx.DialManager.MethodDetail(ctx, req.FullMethodName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{
+		MatchType: MatchedTypeFake,
+	}
+	if !desc.ServerStreaming {
+		resp.MockResp = parser.Fake(desc.Out.RawDesc)
+		return resp, nil
+	}
+
+	for i := rand.Intn(3); i >= 0; i-- {
+		resp.Stream = append(resp.Stream, StreamResponse{
+			MockResp: parser.Fake(desc.Out.RawDesc),
+		})
+	}
+
+	return resp, nil
 }
 
 func (m *Matcher) matchWithMetadata(ctx context.Context, req Request) (*Response, error) {
@@ -70,7 +128,7 @@ func (m *Matcher) matchWithMetadata(ctx context.Context, req Request) (*Response
 	if err != nil {
 		return nil, err
 	}
-	if _case.Body == "" {
+	if _case.Name == "" {
 		return &Response{
 			MatchType: MatchedTypeNone,
 		}, nil
@@ -82,18 +140,7 @@ func (m *Matcher) matchWithMetadata(ctx context.Context, req Request) (*Response
 		return nil, err
 	}
 
-	msg := dynamic.NewMessageFactoryWithDefaults().NewMessage(desc.Out.RawDesc)
-
-	err = jsonpb.Unmarshal(bytes.NewBufferString(_case.Body), msg)
-	if err != nil {
-		logc.Errorf(ctx, "GetMockResponseByMeta jsonpb.Unmarshal body err: %s", err.Error())
-		return nil, err
-	}
-
-	return &Response{
-		MatchType: MatchedTypeMetaData,
-		MockResp:  msg,
-	}, nil
+	return m.mockResponse(ctx, desc, req, _case, MatchedTypeMetaData)
 }
 
 func (m *Matcher) matchWithRequestBody(ctx context.Context, req Request) (*Response, error) {
@@ -102,10 +149,14 @@ func (m *Matcher) matchWithRequestBody(ctx context.Context, req Request) (*Respo
 		return nil, err
 	}
 
-	var ruleCases []types.Case
+	var (
+		ruleCases    []types.Case
+		withMessages bool
+	)
 	for _, _case := range cases {
 		if _case.Rule != "" {
 			ruleCases = append(ruleCases, _case)
+			withMessages = withMessages || rule.UsesMessages(_case.Rule)
 		}
 	}
 	if len(ruleCases) == 0 {
@@ -119,49 +170,177 @@ func (m *Matcher) matchWithRequestBody(ctx context.Context, req Request) (*Respo
 		return nil, err
 	}
 
+	env, err := ruleEnv(desc, req.RawReq, req.RawReqs, withMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, _case := range ruleCases {
+		program, err := m.svcCtx.CaseManager.Program(_case.Rule)
+		if err != nil {
+			continue
+		}
+		output, err := expr.Run(program, env)
+		if err != nil {
+			continue
+		}
+		if v, ok := output.(bool); ok && v {
+			resp, err := m.mockResponse(ctx, desc, req, _case, MatchedTypeBody)
+			if err != nil {
+				continue
+			}
+			return resp, nil
+		}
+	}
+
+	return &Response{
+		MatchType: MatchedTypeNone,
+	}, nil
+}
+
+// ruleEnv builds the environment the case rules are evaluated against, json queries the given
+// request message and messages holds every received one, more than one for streaming calls. The
+// received messages are only decoded withMessages, when a rule reads them.
+func ruleEnv(desc parser.MethodDesc, raw []byte, raws [][]byte, withMessages bool) (map[string]interface{}, error) {
+	in, err := decodeRequest(desc, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []interface{}
+	if withMessages {
+		messages = make([]interface{}, 0, len(raws))
+		for _, raw := range raws {
+			msg, err := decodeRequest(desc, raw)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, messageValue(msg))
+		}
+	}
+
+	query := &messageQuery{msg: in}
+	return rule.Env(query.get, messages), nil
+}
+
+// decodeRequest decodes the raw request message.
+func decodeRequest(desc parser.MethodDesc, raw []byte) (*dynamic.Message, error) {
 	in := dynamic.NewMessage(desc.In.RawDesc)
+	if err := encoding.GetCodec("proto").Unmarshal(raw, in); err != nil {
+		return nil, err
+	}
+
+	return in, nil
+}
+
+// requestJSON decodes the raw request message into its json form.
+func requestJSON(desc parser.MethodDesc, raw []byte) ([]byte, error) {
+	in, err := decodeRequest(desc, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return in.MarshalJSONPB(jsonMarshaler)
+}
 
-	err = encoding.GetCodec("proto").Unmarshal(req.RawReq, in)
+// mockResponse builds the response of the matched case, either a message or a gRPC status error.
+func (m *Matcher) mockResponse(ctx context.Context, desc parser.MethodDesc, req Request, _case types.Case,
+	matchType MatchedType) (*Response, error) {
+	var renderer bodyRender
+	if _case.Template {
+		renderer = newBodyRender(desc, req.FullMethodName, req.MD, req.RawReq, req.RawReqs)
+	}
+
+	header, err := casemanager.Metadata(_case.Headers)
 	if err != nil {
+		logc.Errorf(ctx, "mockResponse case headers err: %s", err.Error())
 		return nil, err
 	}
 
-	js, err := in.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true, EnumsAsInts: true, EmitDefaults: true})
+	trailer, err := casemanager.Metadata(_case.Trailers)
 	if err != nil {
+		logc.Errorf(ctx, "mockResponse case trailers err: %s", err.Error())
 		return nil, err
 	}
 
-	get := func(path string) interface{} {
-		return gjson.Get(string(js), path).Value()
+	st, err := casemanager.Status(_case.Status)
+	if err != nil {
+		logc.Errorf(ctx, "mockResponse case status err: %s", err.Error())
+		return nil, err
 	}
 
-	env := map[string]interface{}{
-		"json": get,
+	resp := &Response{
+		MatchType: matchType,
+		CaseName:  _case.Name,
+		Delay:     casemanager.Delay(_case.Delay),
+		Header:    header,
+		Trailer:   trailer,
+	}
+	if st != nil {
+		resp.Err = st.Err()
 	}
 
-	for _, _case := range ruleCases {
-		output, err := expr.Eval(_case.Rule, env)
+	if desc.ClientStreaming && desc.ServerStreaming && len(_case.Script) > 0 {
+		resp.Script = newConversation(desc, req, _case, m.svcCtx.CaseManager)
+		return resp, nil
+	}
+
+	if desc.ServerStreaming && len(_case.Stream) > 0 {
+		resp.Stream, err = streamResponses(ctx, desc, _case.Stream, renderer)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		if v, ok := output.(bool); ok && v {
-			msg := dynamic.NewMessageFactoryWithDefaults().NewMessage(desc.Out.RawDesc)
+		return resp, nil
+	}
 
-			err = jsonpb.Unmarshal(bytes.NewBufferString(_case.Body), msg)
-			if err != nil {
-				logc.Errorf(ctx, "GetMockResponseByMeta jsonpb.Unmarshal body err: %s", err.Error())
-				continue
-			}
-			return &Response{
-				MatchType: MatchedTypeBody,
-				MockResp:  msg,
-			}, nil
+	if st != nil {
+		return resp, nil
+	}
+
+	resp.MockResp, err = unmarshalBody(ctx, desc, _case.Body, renderer)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func streamResponses(ctx context.Context, desc parser.MethodDesc, messages []types.StreamMessage,
+	renderer bodyRender) ([]StreamResponse, error) {
+	resps := make([]StreamResponse, 0, len(messages))
+	for _, message := range messages {
+		msg, err := unmarshalBody(ctx, desc, message.Body, renderer)
+		if err != nil {
+			return nil, err
 		}
+		resps = append(resps, StreamResponse{
+			MockResp: msg,
+			Delay:    time.Duration(message.Delay) * time.Millisecond,
+		})
 	}
 
-	return &Response{
-		MatchType: MatchedTypeNone,
-	}, nil
+	return resps, nil
+}
+
+func unmarshalBody(ctx context.Context, desc parser.MethodDesc, body string, renderer bodyRender) (proto.Message, error) {
+	if renderer != nil {
+		rendered, err := renderer(body)
+		if err != nil {
+			logc.Errorf(ctx, "mockResponse render body err: %s", err.Error())
+			return nil, err
+		}
+		body = rendered
+	}
+
+	msg := dynamic.NewMessageFactoryWithDefaults().NewMessage(desc.Out.RawDesc)
+
+	err := jsonpb.Unmarshal(bytes.NewBufferString(body), msg)
+	if err != nil {
+		logc.Errorf(ctx, "mockResponse jsonpb.Unmarshal body err: %s", err.Error())
+		return nil, err
+	}
+
+	return msg, nil
 }
 
 func getMetadata(key string, md metadata.MD) string {
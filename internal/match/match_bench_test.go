@@ -0,0 +1,112 @@
+package match
+
+import (
+	"context"
+	"testing"
+
+	"github.com/antonmedv/expr"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/tidwall/gjson"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+var benchRequests = []struct {
+	name string
+	req  string
+	rule string
+}{
+	{
+		name: "flat",
+		req:  `{"id":"a","i32":3,"i64":"42","f64":2.5,"flag":true,"kind":"KIND_A"}`,
+		rule: `json("id") == "a" && json("i32") == 3 && json("i64") == "42" && json("flag") == true`,
+	},
+	{
+		name: "nested",
+		req:  `{"inner":{"name":"in","count":2,"child":{"name":"deep","labels":["x","y"]}}}`,
+		rule: `json("inner.name") == "in" && json("inner.child.name") == "deep" && json("inner.child.labels.1") == "y"`,
+	},
+	{
+		name: "map",
+		req:  `{"by_name":{"a":{"name":"x"},"b":{"name":"y"},"c":{"name":"z"}},"by_num":{"1":"one","2":"two"}}`,
+		rule: `json("by_name.b.name") == "y" && json("by_num.2") == "two"`,
+	},
+	{
+		name: "repeated",
+		req: `{"items":[{"name":"a"},{"name":"b"},{"name":"c"},{"name":"d"},{"name":"e"},{"name":"f"}],
+			"ids":["1","2","3","4","5","6","7","8"]}`,
+		rule: `json("items.#") == 6 && json("items.4.name") == "e" && json("ids.7") == "8"`,
+	},
+}
+
+func benchRaw(b *testing.B, desc parser.MethodDesc, js string) []byte {
+	msg := dynamic.NewMessage(desc.In.RawDesc)
+	if err := msg.UnmarshalJSON([]byte(js)); err != nil {
+		b.Fatal(err)
+	}
+	raw, err := msg.Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return raw
+}
+
+// BenchmarkRuleMatch compares evaluating a case rule on a request the way it used to be done,
+// compiling the rule and building the json form of the request on every call, to the way it is
+// done now, with the rule compiled when its case is set and the fields looked up on the message.
+func BenchmarkRuleMatch(b *testing.B) {
+	desc := testMethod(b)
+
+	for _, bench := range benchRequests {
+		raw := benchRaw(b, desc, bench.req)
+
+		b.Run(bench.name+"/compile+jsonpb+gjson", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				program, err := rule.Compile(bench.rule)
+				if err != nil {
+					b.Fatal(err)
+				}
+				js, err := requestJSON(desc, raw)
+				if err != nil {
+					b.Fatal(err)
+				}
+				env := rule.Env(func(path string) interface{} {
+					return gjson.GetBytes(js, path).Value()
+				}, nil)
+				if output, err := expr.Run(program, env); err != nil || output != true {
+					b.Fatal(output, err)
+				}
+			}
+		})
+
+		b.Run(bench.name+"/cached+lookup", func(b *testing.B) {
+			cases := casemanager.NewManager(nil, nil)
+			cases.Declare(context.Background(), []types.Case{{
+				MethodName: desc.FullName,
+				Name:       bench.name,
+				Rule:       bench.rule,
+			}})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				program, err := cases.Program(bench.rule)
+				if err != nil {
+					b.Fatal(err)
+				}
+				env, err := ruleEnv(desc, raw, nil, false)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if output, err := expr.Run(program, env); err != nil || output != true {
+					b.Fatal(output, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// Data is what a templated body can reference, e.g. {"id": {{json .Request.id}}}.
+type Data struct {
+	Method   string              // full method name
+	Metadata map[string][]string // incoming metadata
+	Request  interface{}         // decoded request message, with the proto field names
+	Messages []interface{}       // every decoded request message received so far
+	Now      time.Time
+}
+
+var funcs = template.FuncMap{
+	// md returns the first value of the incoming metadata key
+	"md": func(md map[string][]string, key string) string {
+		vs := md[strings.ToLower(key)]
+		if len(vs) == 0 {
+			return ""
+		}
+		return vs[0]
+	},
+	// json encodes the value, which quotes strings and keeps numbers, objects and arrays as is
+	"json": func(v interface{}) (string, error) {
+		bs, err := json.Marshal(v)
+		return string(bs), err
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"uuid": func() string {
+		return uuid.NewString()
+	},
+	"randInt": func(min, max int) int {
+		if max <= min {
+			return min
+		}
+		return min + rand.Intn(max-min)
+	},
+	"randString": func(n int) string {
+		b := make([]rune, n)
+		for i := range b {
+			b[i] = letterRunes[rand.Intn(len(letterRunes))]
+		}
+		return string(b)
+	},
+	"now": time.Now,
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"unixMilli": func(t time.Time) int64 {
+		return t.UnixMilli()
+	},
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339Nano)
+	},
+	"add": func(a, b int) int {
+		return a + b
+	},
+}
+
+// Parse parses the templated body.
+func Parse(body string) (*template.Template, error) {
+	return template.New("body").Funcs(funcs).Option("missingkey=zero").Parse(body)
+}
+
+// Render executes the templated body against the data.
+func Render(body string, data *Data) (string, error) {
+	tpl, err := Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,130 @@
+package rule
+
+import (
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+)
+
+// Env builds the environment rules are evaluated against: json queries the request message with
+// a gjson path, e.g. json("items.0.name"), and messages holds every received request message,
+// more than one for streaming calls.
+func Env(json func(path string) interface{}, messages []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"json":     json,
+		"messages": messages,
+	}
+}
+
+// Compile checks the rule against the environment, it must evaluate to a bool.
+func Compile(rule string) (*vm.Program, error) {
+	return expr.Compile(rule, expr.Env(Env(nil, nil)), expr.AsBool(), expr.AsAny())
+}
+
+// Paths returns the constant paths the rule queries the request with json.
+func Paths(rule string) []string {
+	tree, err := parser.Parse(rule)
+	if err != nil {
+		return nil
+	}
+
+	v := &pathVisitor{}
+	ast.Walk(&tree.Node, v)
+
+	return v.paths
+}
+
+type pathVisitor struct {
+	paths []string
+}
+
+func (v *pathVisitor) Visit(node *ast.Node) {
+	call, ok := (*node).(*ast.CallNode)
+	if !ok || len(call.Arguments) != 1 {
+		return
+	}
+	if callee, ok := call.Callee.(*ast.IdentifierNode); !ok || callee.Value != "json" {
+		return
+	}
+	if path, ok := call.Arguments[0].(*ast.StringNode); ok {
+		v.paths = append(v.paths, path.Value)
+	}
+}
+
+// UsesMessages reports whether the rule reads messages, so that they are only decoded when needed.
+func UsesMessages(rule string) bool {
+	tree, err := parser.Parse(rule)
+	if err != nil {
+		return true
+	}
+
+	v := &identifierVisitor{name: "messages"}
+	ast.Walk(&tree.Node, v)
+
+	return v.found
+}
+
+type identifierVisitor struct {
+	name  string
+	found bool
+}
+
+func (v *identifierVisitor) Visit(node *ast.Node) {
+	if ident, ok := (*node).(*ast.IdentifierNode); ok && ident.Value == v.name {
+		v.found = true
+	}
+}
+
+// Conjuncts splits the rule into the conditions that must all hold for it to hold, e.g. the two
+// sides of a && b, the rule itself if it is not such a conjunction.
+func Conjuncts(rule string) []string {
+	tree, err := parser.Parse(rule)
+	if err != nil {
+		return []string{rule}
+	}
+
+	var conds []string
+	var split func(node ast.Node)
+	split = func(node ast.Node) {
+		if binary, ok := node.(*ast.BinaryNode); ok && (binary.Operator == "&&" || binary.Operator == "and") {
+			split(binary.Left)
+			split(binary.Right)
+			return
+		}
+		conds = append(conds, node.String())
+	}
+	split(tree.Node)
+
+	return conds
+}
+
+// Explain evaluates the conditions of the rule one by one against the environment, see Conjuncts,
+// it returns how many hold and the ones that do not.
+func Explain(rule string, env map[string]interface{}) (held int, failed []string) {
+	for _, cond := range Conjuncts(rule) {
+		if Holds(cond, env) {
+			held++
+		} else {
+			failed = append(failed, cond)
+		}
+	}
+
+	return held, failed
+}
+
+// Holds reports whether the rule holds in the environment, an invalid rule never holds.
+func Holds(rule string, env map[string]interface{}) bool {
+	program, err := Compile(rule)
+	if err != nil {
+		return false
+	}
+
+	output, err := expr.Run(program, env)
+	if err != nil {
+		return false
+	}
+	v, ok := output.(bool)
+
+	return ok && v
+}
@@ -0,0 +1,119 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// testEnv is the environment of a request, given in its json form.
+func testEnv(js string, messages ...interface{}) map[string]interface{} {
+	return Env(func(path string) interface{} {
+		return gjson.Get(js, path).Value()
+	}, messages)
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr bool
+	}{
+		{rule: `true`},
+		{rule: `json("id") == "a"`},
+		{rule: `json("id") in ["a", "b"] && len(messages) > 1`},
+		{rule: `json("id") ==`, wantErr: true},
+		{rule: `unknown("id") == "a"`, wantErr: true},
+		{rule: `1 + 1`, wantErr: true},
+		{rule: `"a"`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			_, err := Compile(test.rule)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPaths(t *testing.T) {
+	assert.Equal(t, []string{"id", "items.0.name"}, Paths(`json("id") == "a" && json("items.0.name") != ""`))
+	assert.Empty(t, Paths(`messages[0]["id"] == "a"`))
+	assert.Empty(t, Paths(`json(messages[0]["path"]) == "a"`))
+	assert.Empty(t, Paths(`json("id") ==`))
+}
+
+func TestUsesMessages(t *testing.T) {
+	assert.True(t, UsesMessages(`len(messages) == 2`))
+	assert.True(t, UsesMessages(`json("id") == "a" || messages[0]["id"] == "a"`))
+	assert.False(t, UsesMessages(`json("messages") == "a"`))
+	assert.False(t, UsesMessages(`true`))
+	// an unparsable rule may read them
+	assert.True(t, UsesMessages(`json("id") ==`))
+}
+
+func TestConjuncts(t *testing.T) {
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{rule: `true`, want: []string{`true`}},
+		{rule: `json("a") == 1 && json("b") == 2`, want: []string{`json("a") == 1`, `json("b") == 2`}},
+		{
+			rule: `json("a") == 1 and (json("b") == 2 && json("c") == 3)`,
+			want: []string{`json("a") == 1`, `json("b") == 2`, `json("c") == 3`},
+		},
+		{rule: `json("a") == 1 || json("b") == 2`, want: []string{`json("a") == 1 || json("b") == 2`}},
+		{rule: `json("a") ==`, want: []string{`json("a") ==`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			assert.Equal(t, test.want, Conjuncts(test.rule))
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	env := testEnv(`{"id":"a","n":"1","tags":["x","y"],"score":1.5}`,
+		map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "b"})
+
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{rule: `true`, want: true},
+		{rule: `json("id") == "a"`, want: true},
+		{rule: `json("n") == "1"`, want: true},
+		{rule: `json("score") > 1`, want: true},
+		{rule: `len(json("tags")) == 2 && json("tags.1") == "y"`, want: true},
+		{rule: `json("missing") == nil`, want: true},
+		{rule: `len(messages) == 2 && messages[1]["id"] == "b"`, want: true},
+		{rule: `json("id") == "b"`},
+		{rule: `json("id") ==`},
+		// a runtime error does not hold
+		{rule: `json("missing")["x"] == 1`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			assert.Equal(t, test.want, Holds(test.rule, env))
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	env := testEnv(`{"id":"a","n":"2"}`)
+
+	held, failed := Explain(`json("id") == "a" && json("n") == "1" && json("x") == "y"`, env)
+	assert.Equal(t, 1, held)
+	assert.Equal(t, []string{`json("n") == "1"`, `json("x") == "y"`}, failed)
+
+	held, failed = Explain(`json("id") == "a" && json("n") == "2"`, env)
+	assert.Equal(t, 2, held)
+	assert.Empty(t, failed)
+}
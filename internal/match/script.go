@@ -0,0 +1,83 @@
+package match
+
+import (
+	"context"
+
+	"github.com/antonmedv/expr"
+	"github.com/zeromicro/go-zero/core/logc"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+// Conversation replies to each inbound message of a bidi stream following the script of a case.
+// It is stateful, a new one is built for every matched call.
+type Conversation struct {
+	desc         parser.MethodDesc
+	req          Request
+	_case        types.Case
+	rules        *casemanager.Manager
+	withMessages bool
+	received     [][]byte
+}
+
+func newConversation(desc parser.MethodDesc, req Request, _case types.Case, rules *casemanager.Manager) *Conversation {
+	var withMessages bool
+	for _, step := range _case.Script {
+		withMessages = withMessages || step.Rule != "" && rule.UsesMessages(step.Rule)
+	}
+
+	return &Conversation{
+		desc:         desc,
+		req:          req,
+		_case:        _case,
+		rules:        rules,
+		withMessages: withMessages,
+	}
+}
+
+// Reply evaluates the steps in order against the inbound message and returns the replies of the
+// first one that matches, a step without rule matches every message. end reports whether the
+// matched step closes the stream.
+func (c *Conversation) Reply(ctx context.Context, raw []byte) (replies []StreamResponse, end bool, err error) {
+	c.received = append(c.received, raw)
+
+	env, err := ruleEnv(c.desc, raw, c.received, c.withMessages)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i, step := range c._case.Script {
+		if step.Rule != "" {
+			program, err := c.rules.Program(step.Rule)
+			if err != nil {
+				logc.Errorw(ctx, "script rule compile err", logc.Field("step", i), logc.Field("error", err.Error()))
+				continue
+			}
+			output, err := expr.Run(program, env)
+			if err != nil {
+				logc.Errorw(ctx, "script rule eval err", logc.Field("step", i), logc.Field("error", err.Error()))
+				continue
+			}
+			if v, ok := output.(bool); !ok || !v {
+				continue
+			}
+		}
+
+		var renderer bodyRender
+		if c._case.Template {
+			renderer = newBodyRender(c.desc, c.req.FullMethodName, c.req.MD, raw, c.received)
+		}
+
+		replies, err = streamResponses(ctx, c.desc, step.Reply, renderer)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return replies, step.End, nil
+	}
+
+	return nil, false, nil
+}
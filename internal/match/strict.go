@@ -0,0 +1,124 @@
+package match
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+// maxHints is the number of nearest cases the error of a strict call names.
+const maxHints = 3
+
+// nearCase is a rule case that did not match the request, with how many of its conditions held.
+type nearCase struct {
+	name   string
+	held   int
+	failed []string
+}
+
+// Strict returns the error a call that matched no case fails with when strict mode forbids
+// forwarding it, nil if it may be forwarded. The error hints at the cases nearest to matching.
+func (m *Matcher) Strict(ctx context.Context, req Request) error {
+	conf := m.svcCtx.Config.MatchConf.Strict
+	if _, ok := m.strictMethods[req.FullMethodName]; !conf.Enabled && !ok {
+		return nil
+	}
+
+	msg := conf.Message
+	if msg == "" {
+		msg = fmt.Sprintf("no case of %s matched and strict mode forbids forwarding it", req.FullMethodName)
+	}
+	if hints := m.hints(ctx, req); len(hints) > 0 {
+		msg += "; " + strings.Join(hints, "; ")
+	}
+
+	return status.Error(m.strictCode, msg)
+}
+
+// hints explains why no case of the method matched the request: the case named in the metadata
+// does not exist, the rules of the nearest cases do not hold, or the method has no case at all.
+func (m *Matcher) hints(ctx context.Context, req Request) []string {
+	conf := m.svcCtx.Config.MatchConf
+
+	var hints []string
+	if caseName := getMetadata(conf.MockCaseKey, req.MD); caseName != "" {
+		if _case, err := m.svcCtx.CaseManager.CaseGet(ctx, req.FullMethodName, caseName); err != nil || _case.Name == "" {
+			hints = append(hints, fmt.Sprintf("metadata %s names %q which is not a case of the method",
+				conf.MockCaseKey, caseName))
+		} else {
+			hints = append(hints, fmt.Sprintf("metadata %s names case %s but %s is not %q",
+				conf.MockCaseKey, caseName, conf.MockEnableKey, conf.MockEnableValue))
+		}
+	}
+
+	cases, err := m.svcCtx.CaseManager.CaseList(ctx, req.FullMethodName)
+	if err != nil || len(cases) == 0 {
+		return append(hints, "the method has no case")
+	}
+
+	var noRule []string
+	for _, _case := range cases {
+		if _case.Rule == "" {
+			noRule = append(noRule, _case.Name)
+		}
+	}
+
+	for i, near := range m.nearest(ctx, req, cases) {
+		if i == maxHints {
+			break
+		}
+		if len(near.failed) == 0 {
+			hints = append(hints, fmt.Sprintf("case %s: its rule holds but its response could not be built", near.name))
+			continue
+		}
+		hints = append(hints, fmt.Sprintf("case %s: %d of %d conditions hold, failing: %s", near.name, near.held,
+			near.held+len(near.failed), strings.Join(near.failed, ", ")))
+	}
+	if len(noRule) > 0 {
+		hints = append(hints, fmt.Sprintf("cases without rule, selected by metadata %s only: %s",
+			conf.MockCaseKey, strings.Join(noRule, ", ")))
+	}
+
+	return hints
+}
+
+// nearest evaluates the conditions of the rule cases one by one against the request, the cases
+// where most of them hold come first.
+func (m *Matcher) nearest(ctx context.Context, req Request, cases []types.Case) []nearCase {
+	desc, err := m.svcCtx.DialManager.MethodDetail(ctx, req.FullMethodName)
+	if err != nil {
+		return nil
+	}
+
+	var withMessages bool
+	for _, _case := range cases {
+		withMessages = withMessages || _case.Rule != "" && rule.UsesMessages(_case.Rule)
+	}
+	env, err := ruleEnv(desc, req.RawReq, req.RawReqs, withMessages)
+	if err != nil {
+		return nil
+	}
+
+	var nears []nearCase
+	for _, _case := range cases {
+		if _case.Rule == "" {
+			continue
+		}
+
+		near := nearCase{name: _case.Name}
+		near.held, near.failed = rule.Explain(_case.Rule, env)
+		nears = append(nears, near)
+	}
+
+	sort.SliceStable(nears, func(i, j int) bool {
+		return nears[i].held*(nears[j].held+len(nears[j].failed)) > nears[j].held*(nears[i].held+len(nears[i].failed))
+	})
+
+	return nears
+}
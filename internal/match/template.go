@@ -0,0 +1,57 @@
+package match
+
+import (
+	"time"
+
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/render"
+)
+
+// bodyRender renders the body of a templated case, it is nil for plain cases.
+type bodyRender func(body string) (string, error)
+
+// newBodyRender returns the renderer of the bodies replying to the given request messages,
+// the request is only decoded once the first body is rendered.
+func newBodyRender(desc parser.MethodDesc, method string, md metadata.MD, raw []byte, raws [][]byte) bodyRender {
+	var data *render.Data
+
+	return func(body string) (string, error) {
+		if data == nil {
+			d, err := templateData(desc, method, md, raw, raws)
+			if err != nil {
+				return "", err
+			}
+			data = d
+		}
+
+		return render.Render(body, data)
+	}
+}
+
+func templateData(desc parser.MethodDesc, method string, md metadata.MD, raw []byte,
+	raws [][]byte) (*render.Data, error) {
+	js, err := requestJSON(desc, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]interface{}, 0, len(raws))
+	for _, raw := range raws {
+		msgJs, err := requestJSON(desc, raw)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, gjson.ParseBytes(msgJs).Value())
+	}
+
+	return &render.Data{
+		Method:   method,
+		Metadata: md,
+		Request:  gjson.ParseBytes(js).Value(),
+		Messages: messages,
+		Now:      time.Now(),
+	}, nil
+}
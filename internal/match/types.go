@@ -1,13 +1,16 @@
 package match
 
 import (
+	"time"
+
 	"google.golang.org/grpc/metadata"
 )
 
 type Request struct {
 	FullMethodName string
 	MD             metadata.MD
-	RawReq         []byte
+	RawReq         []byte   // the first request message
+	RawReqs        [][]byte // every request message received before matching, more than one for client streaming
 }
 
 type MatchedType int
@@ -16,9 +19,35 @@ const (
 	MatchedTypeNone     MatchedType = 0
 	MatchedTypeMetaData MatchedType = 1
 	MatchedTypeBody     MatchedType = 2
+	MatchedTypeFake     MatchedType = 3 // no case matched, the response is generated from the schema
 )
 
+func (t MatchedType) String() string {
+	switch t {
+	case MatchedTypeMetaData:
+		return "metadata"
+	case MatchedTypeBody:
+		return "body"
+	case MatchedTypeFake:
+		return "fake"
+	default:
+		return "none"
+	}
+}
+
 type Response struct {
 	MatchType MatchedType
+	CaseName  string
 	MockResp  interface{}
+	Stream    []StreamResponse // messages of a server streaming reply, sent instead of MockResp
+	Script    *Conversation    // replies to each inbound message of a bidi stream, used instead of MockResp
+	Err       error            // status error to reply with instead of MockResp, or after Stream and Script
+	Delay     time.Duration
+	Header    metadata.MD
+	Trailer   metadata.MD
+}
+
+type StreamResponse struct {
+	MockResp interface{}
+	Delay    time.Duration // delay before sending this message
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/proto" // must be registered before the proxy codec overrides it
 	"google.golang.org/protobuf/proto"
 
 	protoV1 "github.com/golang/protobuf/proto" //nolint
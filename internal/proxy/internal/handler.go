@@ -5,16 +5,21 @@ package internal
 
 import (
 	"io"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/journal"
 	"github.com/zeromicro/grpc-mock/internal/match"
 	"github.com/zeromicro/grpc-mock/internal/proxy/internal/codec"
+	"github.com/zeromicro/grpc-mock/internal/record"
 )
 
 var clientStreamDescForProxying = &grpc.StreamDesc{
@@ -28,10 +33,20 @@ var clientStreamDescForProxying = &grpc.StreamDesc{
 //
 // This can *only* be used if the `server` also uses grpcproxy.CodecForServer() ServerOption.
 func TransparentHandler(director func(ctx context.Context, fullMethodName string) (grpc.ClientConnInterface, error),
-	match func(ctx context.Context, req match.Request) (*match.Response, error)) grpc.StreamHandler {
+	describe func(ctx context.Context, fullMethodName string) (parser.MethodDesc, error),
+	match func(ctx context.Context, req match.Request) (*match.Response, error),
+	strict func(ctx context.Context, req match.Request) error,
+	recording func(ctx context.Context, fullMethodName string) bool,
+	record func(ctx context.Context, call record.Call) error,
+	observe func(ctx context.Context, call journal.Call)) grpc.StreamHandler {
 	streamer := &handler{
-		director: director,
-		match:    match,
+		director:  director,
+		describe:  describe,
+		match:     match,
+		strict:    strict,
+		recording: recording,
+		record:    record,
+		observe:   observe,
 	}
 	return streamer.handler
 }
@@ -43,15 +58,21 @@ type ReqMeta struct {
 }
 
 type handler struct {
-	director func(ctx context.Context, fullMethodName string) (grpc.ClientConnInterface, error)
-	match    func(ctx context.Context, req match.Request) (*match.Response, error)
+	director  func(ctx context.Context, fullMethodName string) (grpc.ClientConnInterface, error)
+	describe  func(ctx context.Context, fullMethodName string) (parser.MethodDesc, error)
+	match     func(ctx context.Context, req match.Request) (*match.Response, error)
+	strict    func(ctx context.Context, req match.Request) error
+	recording func(ctx context.Context, fullMethodName string) bool
+	record    func(ctx context.Context, call record.Call) error
+	observe   func(ctx context.Context, call journal.Call)
 }
 
 // handler is where the real magic of proxying happens.
 // It is invoked like any gRPC server stream and uses the gRPC server framing to get and receive bytes from the wire,
 // forwarding it to a ClientStream established against the relevant ClientConn.
-func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) error {
+func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) (err error) {
 	ctx := serverStream.Context()
+	start := time.Now()
 
 	logger := logx.WithContext(ctx)
 
@@ -69,8 +90,18 @@ func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) error
 	}
 	logger.Infow("handler get md", logx.Field("md", md))
 
-	// We require that the director's returned context inherits from the serverStream.Context().
-	backendConn, err := h.director(ctx, fullMethodName)
+	// every call is journaled once it is over, whether it was mocked, forwarded or failed
+	observed := journal.Call{
+		FullMethodName: fullMethodName,
+		MD:             md,
+		Start:          start,
+	}
+	defer func() {
+		observed.Err = err
+		h.observe(context.Background(), observed)
+	}()
+
+	desc, err := h.describe(ctx, fullMethodName)
 	if err != nil {
 		return err
 	}
@@ -78,32 +109,50 @@ func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) error
 	// forward user client metadata to upstream server
 	ctx = metadata.NewOutgoingContext(ctx, md.Copy())
 
-	var (
-		reqBytes []byte
-		mt       match.MatchedType
-	)
+	var mt match.MatchedType
 
-	f := &codec.Frame{}
-	for i := 0; ; i++ {
-		if err := serverStream.RecvMsg(f); err != nil {
-			break
-		}
+	reqs, clientDone, err := receive(serverStream, desc)
+	if err != nil {
+		return err
 	}
-	reqBytes = f.GetBytes()
+	observed.Reqs = reqs
 
 	reqMeta := &ReqMeta{
 		FullMethodName: fullMethodName,
 		MD:             md,
 	}
 
-	mt = h.doMock(serverStream, reqBytes, reqMeta, logger)
-	if mt != match.MatchedTypeNone {
-		logger.Infow("matched succeed.", logx.Field("match_type", mt))
-		return nil
+	// recorded calls are always forwarded, so that the cases they were recorded into do not answer
+	// them; bidi streams are not recorded since their replies interleave with the requests
+	var call *record.Call
+	if !(desc.ClientStreaming && desc.ServerStreaming) && h.recording(ctx, fullMethodName) {
+		call = &record.Call{
+			FullMethodName: fullMethodName,
+			Reqs:           reqs,
+		}
+	} else {
+		mt, observed.CaseName, err = h.doMock(serverStream, reqs, reqMeta, logger)
+		observed.MatchType = mt.String()
+		if mt != match.MatchedTypeNone {
+			logger.Infow("matched succeed.", logx.Field("match_type", mt))
+			return err
+		}
+
+		if err = h.strict(ctx, newRequest(reqs, reqMeta)); err != nil {
+			logger.Infow("unmatched call not forwarded in strict mode", logx.Field("error", err))
+			return err
+		}
 	}
 
 	logger.Infof("grpc-mock act as a proxy")
 
+	// the director is only asked once no case matched, so that disabled upstreams still serve cases
+	backendConn, err := h.director(ctx, fullMethodName)
+	if err != nil {
+		logger.Errorw("handler director err", logx.Field("error", err))
+		return status.Errorf(codes.Unavailable, "upstream of %s unavailable: %v", fullMethodName, err)
+	}
+
 	clientCtx, clientCancel := context.WithCancel(ctx)
 	defer clientCancel()
 
@@ -111,11 +160,12 @@ func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) error
 	if err != nil {
 		return err
 	}
+	observed.Forwarded = true
 	// Explicitly *do not close* s2cErrChan and c2sErrChan, otherwise the select below will not terminate.
 	// Channels do not have to be closed, it is just a control flow mechanism, see
 	// https://groups.google.com/forum/#!msg/golang-nuts/pZwdYRGxCIk/qpbHxRRPJdUJ
-	s2cErrChan := h.forwardClientToServer(serverStream, clientStream, reqBytes)
-	c2sErrChan := h.forwardServerToClient(clientStream, serverStream)
+	s2cErrChan := h.forwardClientToServer(serverStream, clientStream, reqs, clientDone)
+	c2sErrChan := h.forwardServerToClient(clientStream, serverStream, call)
 	// We don't know which side is going to stop sending first, so we need a select between the two.
 	for i := 0; i < 2; i++ {
 		select {
@@ -136,6 +186,9 @@ func (h *handler) handler(srv interface{}, serverStream grpc.ServerStream) error
 			// cases we may have received Trailers as part of the call. In case of other errors (stream closed) the trailers
 			// will be nil.
 			serverStream.SetTrailer(clientStream.Trailer())
+			if call != nil {
+				h.recordCall(clientStream, call, c2sErr, logger)
+			}
 			// c2sErr will contain RPC error from client code. If not io.EOF return the RPC error as server stream error.
 			if c2sErr != io.EOF {
 				return c2sErr
@@ -154,7 +207,28 @@ func getMetadata(key string, md metadata.MD) string {
 	return vs[0]
 }
 
-func (h *handler) forwardServerToClient(src grpc.ClientStream, dst grpc.ServerStream) chan error {
+// recordCall completes the forwarded call with what the upstream answered and records it in the
+// background, once the upstream is done.
+func (h *handler) recordCall(src grpc.ClientStream, call *record.Call, srcErr error, logger logx.Logger) {
+	call.Header, _ = src.Header()
+	call.Trailer = src.Trailer()
+	if srcErr != io.EOF {
+		call.Err = srcErr
+	}
+
+	threading.GoSafe(func() {
+		if err := h.record(context.Background(), *call); err != nil {
+			logger.Errorw("record call err", logx.Field("method_name", call.FullMethodName), logx.Field("error", err))
+			return
+		}
+
+		logger.Infow("call recorded", logx.Field("method_name", call.FullMethodName))
+	})
+}
+
+// forwardServerToClient pumps the responses of the upstream to the client, they are also kept in
+// call if it is recorded.
+func (h *handler) forwardServerToClient(src grpc.ClientStream, dst grpc.ServerStream, call *record.Call) chan error {
 	ret := make(chan error, 1)
 	go func() {
 		f := &codec.Frame{}
@@ -163,6 +237,9 @@ func (h *handler) forwardServerToClient(src grpc.ClientStream, dst grpc.ServerSt
 				ret <- err // this can be io.EOF which is happy case
 				break
 			}
+			if call != nil {
+				call.Resps = append(call.Resps, f.GetBytes())
+			}
 			if i == 0 {
 				// This is a bit of a hack, but client to server headers are only readable after first client msg is
 				// received but must be written to server stream before the first msg is flushed.
@@ -186,64 +263,198 @@ func (h *handler) forwardServerToClient(src grpc.ClientStream, dst grpc.ServerSt
 	return ret
 }
 
-func (h *handler) forwardClientToServer(src grpc.ServerStream, dst grpc.ClientStream, reqBytes []byte) chan error {
+// receive reads the request messages needed for matching: all of them for client streaming calls,
+// only the first one otherwise, so that bidi streams are never buffered. It reports whether the
+// client has already half-closed its side of the stream.
+func receive(src grpc.ServerStream, desc parser.MethodDesc) ([][]byte, bool, error) {
+	var reqs [][]byte
+	for {
+		f := &codec.Frame{}
+		if err := src.RecvMsg(f); err != nil {
+			if err == io.EOF {
+				return reqs, true, nil
+			}
+			return nil, false, err
+		}
+
+		reqs = append(reqs, f.GetBytes())
+		if !desc.ClientStreaming || desc.ServerStreaming {
+			return reqs, false, nil
+		}
+	}
+}
+
+// forwardClientToServer sends the already received requests, then keeps pumping the ones that are
+// still to come unless the client has already half-closed the stream.
+func (h *handler) forwardClientToServer(src grpc.ServerStream, dst grpc.ClientStream, reqs [][]byte,
+	srcDone bool) chan error {
 	ret := make(chan error, 1)
 	go func() {
-		f := codec.NewFrame(reqBytes)
-		for i := 0; ; i++ {
-			if err := dst.SendMsg(f); err != nil {
+		for _, req := range reqs {
+			if err := dst.SendMsg(codec.NewFrame(req)); err != nil {
 				ret <- err
-				break
+				return
 			}
+		}
 
+		if srcDone {
+			ret <- io.EOF
+			return
+		}
+
+		f := &codec.Frame{}
+		for i := 0; ; i++ {
 			if err := src.RecvMsg(f); err != nil {
 				ret <- err // this can be io.EOF which is happy case
 				break
 			}
 
+			if err := dst.SendMsg(f); err != nil {
+				ret <- err
+				break
+			}
 		}
 	}()
 
 	return ret
 }
 
-func (h *handler) doMock(src grpc.ServerStream, reqBytes []byte, meta *ReqMeta, logger logx.Logger) match.MatchedType {
-	var (
-		matched  bool
-		response interface{}
-	)
+func newRequest(reqs [][]byte, meta *ReqMeta) match.Request {
+	req := match.Request{
+		FullMethodName: meta.FullMethodName,
+		MD:             meta.MD,
+		RawReqs:        reqs,
+	}
+	if len(reqs) > 0 {
+		req.RawReq = reqs[0]
+	}
 
-	defer func() {
-		if matched {
-			src.SetTrailer(metadata.MD{})
+	return req
+}
 
-			header := map[string][]string{"mock": {"matched"}}
-			src.SetHeader(header)
+func (h *handler) doMock(src grpc.ServerStream, reqs [][]byte, meta *ReqMeta, logger logx.Logger) (match.MatchedType,
+	string, error) {
+	resp, err := h.match(context.Background(), newRequest(reqs, meta))
+	if err != nil {
+		logger.Errorw("match err", logx.Field("error", err))
+		return match.MatchedTypeNone, "", nil
+	}
 
-			err := src.SendMsg(response)
-			if err != nil {
-				logger.Errorw("send msg err", logx.Field("error", err))
-			}
+	if resp.MatchType == match.MatchedTypeNone {
+		return match.MatchedTypeNone, "", nil
+	}
 
-			logger.Infow("matched succeed.", logx.Field("response", response), logx.Field("header", header))
+	if err = wait(src.Context(), resp.Delay); err != nil {
+		// the caller gave up first, e.g. its deadline expired while we were sleeping
+		logger.Infow("matched but caller gone before delay elapsed", logx.Field("case", resp.CaseName),
+			logx.Field("delay", resp.Delay))
+		return resp.MatchType, resp.CaseName, err
+	}
+
+	src.SetTrailer(resp.Trailer)
+
+	header := metadata.Join(resp.Header, metadata.Pairs("mock", "matched"))
+	src.SetHeader(header)
+
+	if resp.Script != nil {
+		if err = converse(src, reqs, resp.Script, logger); err != nil {
+			return resp.MatchType, resp.CaseName, err
 		}
-	}()
 
-	resp, err := h.match(context.Background(), match.Request{
-		FullMethodName: meta.FullMethodName,
-		MD:             meta.MD,
-		RawReq:         reqBytes,
-	})
+		logger.Infow("matched succeed.", logx.Field("case", resp.CaseName), logx.Field("script", true),
+			logx.Field("error", resp.Err), logx.Field("header", header), logx.Field("trailer", resp.Trailer))
+		return resp.MatchType, resp.CaseName, resp.Err
+	}
+
+	if len(resp.Stream) > 0 {
+		if err = sendStream(src, resp.Stream, logger); err != nil {
+			return resp.MatchType, resp.CaseName, err
+		}
+
+		logger.Infow("matched succeed.", logx.Field("case", resp.CaseName), logx.Field("stream", len(resp.Stream)),
+			logx.Field("error", resp.Err), logx.Field("header", header), logx.Field("trailer", resp.Trailer))
+		return resp.MatchType, resp.CaseName, resp.Err
+	}
+
+	if resp.Err != nil {
+		logger.Infow("matched succeed.", logx.Field("case", resp.CaseName), logx.Field("error", resp.Err),
+			logx.Field("header", header), logx.Field("trailer", resp.Trailer))
+		return resp.MatchType, resp.CaseName, resp.Err
+	}
+
+	err = src.SendMsg(resp.MockResp)
 	if err != nil {
-		logger.Errorw("match err", logx.Field("error", err))
-		return resp.MatchType
+		logger.Errorw("send msg err", logx.Field("error", err))
+	}
+
+	logger.Infow("matched succeed.", logx.Field("case", resp.CaseName), logx.Field("response", resp.MockResp),
+		logx.Field("header", header), logx.Field("trailer", resp.Trailer))
+
+	return resp.MatchType, resp.CaseName, nil
+}
+
+// converse answers every inbound message of a bidi stream with the replies of the script, starting
+// with the already received ones, until the client half-closes or a step ends the conversation.
+func converse(src grpc.ServerStream, reqs [][]byte, script *match.Conversation, logger logx.Logger) error {
+	reply := func(req []byte) (bool, error) {
+		replies, end, err := script.Reply(src.Context(), req)
+		if err != nil {
+			logger.Errorw("script reply err", logx.Field("error", err))
+			return false, status.Errorf(codes.InvalidArgument, "script reply: %v", err)
+		}
+
+		return end, sendStream(src, replies, logger)
+	}
+
+	for _, req := range reqs {
+		if end, err := reply(req); end || err != nil {
+			return err
+		}
+	}
+
+	for {
+		f := &codec.Frame{}
+		if err := src.RecvMsg(f); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if end, err := reply(f.GetBytes()); end || err != nil {
+			return err
+		}
 	}
+}
+
+func sendStream(src grpc.ServerStream, stream []match.StreamResponse, logger logx.Logger) error {
+	for i, msg := range stream {
+		if err := wait(src.Context(), msg.Delay); err != nil {
+			return err
+		}
 
-	if resp.MatchType != match.MatchedTypeNone {
-		matched = true
-		response = resp.MockResp
-		return resp.MatchType
+		if err := src.SendMsg(msg.MockResp); err != nil {
+			logger.Errorw("send stream msg err", logx.Field("index", i), logx.Field("error", err))
+			return err
+		}
 	}
 
-	return match.MatchedTypeNone
+	return nil
+}
+
+// wait sleeps for d, it returns the status error of ctx if ctx is done first.
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
 }
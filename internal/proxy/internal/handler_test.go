@@ -0,0 +1,380 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match"
+	"github.com/zeromicro/grpc-mock/internal/proxy/internal/codec"
+	"github.com/zeromicro/grpc-mock/internal/svc"
+)
+
+var errBroken = errors.New("broken")
+
+// testServerStream receives the given frames, then err, io.EOF if nil, and records the messages
+// sent on it.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	reqs     [][]byte
+	err      error
+	received int
+	sent     []interface{}
+}
+
+func newTestServerStream(err error, reqs ...string) *testServerStream {
+	s := &testServerStream{ctx: context.Background(), err: err}
+	for _, req := range reqs {
+		s.reqs = append(s.reqs, []byte(req))
+	}
+
+	return s
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testServerStream) RecvMsg(m interface{}) error {
+	if s.received == len(s.reqs) {
+		if s.err != nil {
+			return s.err
+		}
+		return io.EOF
+	}
+
+	*m.(*codec.Frame) = *codec.NewFrame(s.reqs[s.received])
+	s.received++
+
+	return nil
+}
+
+func (s *testServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+// testClientStream records the frames sent on it, sending fails with err once limit are sent.
+type testClientStream struct {
+	grpc.ClientStream
+	sent  []string
+	limit int
+	err   error
+}
+
+func (s *testClientStream) SendMsg(m interface{}) error {
+	if s.err != nil && len(s.sent) == s.limit {
+		return s.err
+	}
+
+	s.sent = append(s.sent, string(m.(*codec.Frame).GetBytes()))
+	return nil
+}
+
+func frames(reqs [][]byte) []string {
+	var ret []string
+	for _, req := range reqs {
+		ret = append(ret, string(req))
+	}
+
+	return ret
+}
+
+func TestReceive(t *testing.T) {
+	tests := []struct {
+		name     string
+		desc     parser.MethodDesc
+		src      *testServerStream
+		want     []string
+		done     bool
+		received int
+		wantErr  error
+	}{
+		{
+			name:     "unary",
+			src:      newTestServerStream(nil, "a"),
+			want:     []string{"a"},
+			received: 1,
+		},
+		{
+			name:     "server streaming",
+			desc:     parser.MethodDesc{ServerStreaming: true},
+			src:      newTestServerStream(nil, "a"),
+			want:     []string{"a"},
+			received: 1,
+		},
+		{
+			name:     "bidi",
+			desc:     parser.MethodDesc{ClientStreaming: true, ServerStreaming: true},
+			src:      newTestServerStream(nil, "a", "b", "c"),
+			want:     []string{"a"},
+			received: 1,
+		},
+		{
+			name:     "client streaming",
+			desc:     parser.MethodDesc{ClientStreaming: true},
+			src:      newTestServerStream(nil, "a", "b", "c"),
+			want:     []string{"a", "b", "c"},
+			done:     true,
+			received: 3,
+		},
+		{
+			name: "client streaming without messages",
+			desc: parser.MethodDesc{ClientStreaming: true},
+			src:  newTestServerStream(nil),
+			done: true,
+		},
+		{
+			name:     "client streaming error",
+			desc:     parser.MethodDesc{ClientStreaming: true},
+			src:      newTestServerStream(errBroken, "a"),
+			received: 1,
+			wantErr:  errBroken,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reqs, done, err := receive(test.src, test.desc)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, frames(reqs))
+			assert.Equal(t, test.done, done)
+			assert.Equal(t, test.received, test.src.received)
+		})
+	}
+}
+
+func TestForwardClientToServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		reqs     []string
+		srcDone  bool
+		src      *testServerStream
+		dst      *testClientStream
+		want     []string
+		wantErr  error
+		received int
+	}{
+		{
+			name:     "replays then pumps",
+			reqs:     []string{"a", "b"},
+			src:      newTestServerStream(nil, "c", "d"),
+			dst:      &testClientStream{},
+			want:     []string{"a", "b", "c", "d"},
+			wantErr:  io.EOF,
+			received: 2,
+		},
+		{
+			name:    "source done",
+			reqs:    []string{"a", "b"},
+			srcDone: true,
+			src:     newTestServerStream(nil, "c"),
+			dst:     &testClientStream{},
+			want:    []string{"a", "b"},
+			wantErr: io.EOF,
+		},
+		{
+			name:     "source error",
+			reqs:     []string{"a"},
+			src:      newTestServerStream(errBroken, "b"),
+			dst:      &testClientStream{},
+			want:     []string{"a", "b"},
+			wantErr:  errBroken,
+			received: 1,
+		},
+		{
+			name:    "replay error",
+			reqs:    []string{"a", "b"},
+			src:     newTestServerStream(nil, "c"),
+			dst:     &testClientStream{limit: 1, err: errBroken},
+			want:    []string{"a"},
+			wantErr: errBroken,
+		},
+		{
+			name:     "pump error",
+			reqs:     []string{"a"},
+			src:      newTestServerStream(nil, "b", "c"),
+			dst:      &testClientStream{limit: 2, err: errBroken},
+			want:     []string{"a", "b"},
+			wantErr:  errBroken,
+			received: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reqs [][]byte
+			for _, req := range test.reqs {
+				reqs = append(reqs, []byte(req))
+			}
+
+			h := &handler{}
+			err := <-h.forwardClientToServer(test.src, test.dst, reqs, test.srcDone)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, test.dst.sent)
+			assert.Equal(t, test.received, test.src.received)
+		})
+	}
+}
+
+const testProto = `syntax = "proto3";
+package test;
+
+message Msg {
+  string id = 1;
+}
+
+service Echo {
+  rpc Chat(stream Msg) returns (stream Msg);
+}
+`
+
+const testMethod = "/test.Echo/Chat"
+
+// testConversation starts the conversation scripted by the steps for a call whose first message is
+// first, the way the matcher does.
+func testConversation(t *testing.T, first []byte, steps []types.ScriptStep) *match.Conversation {
+	ctx := context.Background()
+
+	var upstream dialmanager.RpcClientConf
+	conf.FillDefault(&upstream)
+	upstream.Name = "echo"
+	upstream.Protos = map[string]string{"test.proto": testProto}
+	dialManager := dialmanager.NewManager()
+	require.NoError(t, dialManager.AddUpstream(ctx, []dialmanager.RpcClientConf{upstream}))
+
+	caseManager := casemanager.NewManager(nil, dialManager)
+	caseManager.Declare(ctx, []types.Case{{
+		MethodName: testMethod,
+		Name:       "script",
+		Rule:       "true",
+		Script:     steps,
+	}})
+
+	svcCtx := &svc.ServiceContext{DialManager: dialManager, CaseManager: caseManager}
+	svcCtx.Config.MatchConf.Strict.Code = codes.NotFound.String()
+
+	resp, err := match.NewMatcher(svcCtx).Match(ctx, match.Request{
+		FullMethodName: testMethod,
+		RawReq:         first,
+		RawReqs:        [][]byte{first},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Script)
+
+	return resp.Script
+}
+
+func testMsg(t *testing.T, id string) []byte {
+	svcs, err := parser.ParseProtos(map[string]string{"test.proto": testProto}, nil, nil)
+	require.NoError(t, err)
+
+	msg := dynamic.NewMessage(svcs[0].Methods[0].In.RawDesc)
+	msg.SetFieldByName("id", id)
+	raw, err := msg.Marshal()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func sentJSON(t *testing.T, sent []interface{}) []string {
+	var ret []string
+	for _, msg := range sent {
+		js, err := (&jsonpb.Marshaler{}).MarshalToString(msg.(proto.Message))
+		require.NoError(t, err)
+		ret = append(ret, js)
+	}
+
+	return ret
+}
+
+func TestConverse(t *testing.T) {
+	steps := []types.ScriptStep{
+		{Rule: `json("id") == "hi"`, Reply: []types.StreamMessage{{Body: `{"id":"hello"}`}}},
+		{Rule: `json("id") == "bye"`, Reply: []types.StreamMessage{{Body: `{"id":"bye"}`}}, End: true},
+		{Rule: `json("id") == "twice"`, Reply: []types.StreamMessage{{Body: `{"id":"1"}`}, {Body: `{"id":"2"}`}}},
+	}
+
+	tests := []struct {
+		name     string
+		reqs     []string
+		src      []string
+		srcErr   error
+		want     []string
+		received int
+		wantErr  error
+	}{
+		{
+			name:     "until half-close",
+			reqs:     []string{"hi"},
+			src:      []string{"other", "twice"},
+			want:     []string{`{"id":"hello"}`, `{"id":"1"}`, `{"id":"2"}`},
+			received: 2,
+		},
+		{
+			name:     "until end",
+			reqs:     []string{"hi"},
+			src:      []string{"bye", "hi"},
+			want:     []string{`{"id":"hello"}`, `{"id":"bye"}`},
+			received: 1,
+		},
+		{
+			name: "ended by a received message",
+			reqs: []string{"bye"},
+			src:  []string{"hi"},
+			want: []string{`{"id":"bye"}`},
+		},
+		{
+			name:    "receive error",
+			reqs:    []string{"hi"},
+			srcErr:  errBroken,
+			want:    []string{`{"id":"hello"}`},
+			wantErr: errBroken,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reqs [][]byte
+			for _, req := range test.reqs {
+				reqs = append(reqs, testMsg(t, req))
+			}
+			src := &testServerStream{ctx: context.Background(), err: test.srcErr}
+			for _, req := range test.src {
+				src.reqs = append(src.reqs, testMsg(t, req))
+			}
+
+			err := converse(src, reqs, testConversation(t, reqs[0], steps), logx.WithContext(src.ctx))
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, sentJSON(t, src.sent))
+			assert.Equal(t, test.received, src.received)
+		})
+	}
+}
+
+func TestConverseInvalidMessage(t *testing.T) {
+	first := testMsg(t, "hi")
+	script := testConversation(t, first, []types.ScriptStep{{Reply: []types.StreamMessage{{Body: `{}`}}}})
+
+	src := newTestServerStream(nil, "\xff")
+	err := converse(src, [][]byte{first}, script, logx.WithContext(src.ctx))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Len(t, src.sent, 1)
+}
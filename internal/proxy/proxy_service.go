@@ -5,6 +5,7 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
 	"github.com/zeromicro/grpc-mock/internal/match"
 	internal2 "github.com/zeromicro/grpc-mock/internal/proxy/internal"
 	"github.com/zeromicro/grpc-mock/internal/svc"
@@ -17,13 +18,18 @@ type Proxy struct {
 }
 
 func NewProxy(svcCtx *svc.ServiceContext) *Proxy {
-	s := zrpc.MustNewServer(svcCtx.Config.ProxyService.RpcServerConf, func(server *grpc.Server) {})
+	s := zrpc.MustNewServer(svcCtx.Config.ProxyService.RpcServerConf, func(server *grpc.Server) {
+		registerReflection(server, svcCtx.DialManager)
+	})
 	matcher := match.NewMatcher(svcCtx)
 
 	s.AddOptions(grpc.UnknownServiceHandler(
 		internal2.TransparentHandler(func(ctx context.Context, fullMethodName string) (grpc.ClientConnInterface, error) {
 			return svcCtx.DialManager.UpstreamClient(ctx, fullMethodName)
-		}, matcher.Match)))
+		}, func(ctx context.Context, fullMethodName string) (parser.MethodDesc, error) {
+			return svcCtx.DialManager.MethodDetail(ctx, fullMethodName)
+		}, matcher.Match, matcher.Strict, svcCtx.Recorder.Recording, svcCtx.Recorder.Record,
+			svcCtx.Journal.Add)))
 	return &Proxy{
 		s:       s,
 		svcCtx:  svcCtx,
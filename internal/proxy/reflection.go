@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+)
+
+// reflectionResolver feeds the reflection service of the proxy with the services of the proxy
+// itself and the union of the upstream ones, descriptors are looked up in the upstream schemas
+// first, then in the ones linked into the binary.
+type reflectionResolver struct {
+	server  *grpc.Server
+	manager *dialmanager.Manager
+}
+
+// registerReflection serves both grpc.reflection.v1 and v1alpha on the server.
+func registerReflection(server *grpc.Server, manager *dialmanager.Manager) {
+	r := &reflectionResolver{
+		server:  server,
+		manager: manager,
+	}
+	opts := reflection.ServerOptions{
+		Services:           r,
+		DescriptorResolver: r,
+		ExtensionResolver:  r,
+	}
+
+	grpc_reflection_v1.RegisterServerReflectionServer(server, reflection.NewServerV1(opts))
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(server, reflection.NewServer(opts))
+}
+
+func (r *reflectionResolver) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := r.server.GetServiceInfo()
+
+	services, _ := r.manager.Methods(context.Background())
+	for svc, methods := range services {
+		if _, ok := info[svc]; ok {
+			// the proxy serves it itself, e.g. reflection, upstreams never get those calls
+			continue
+		}
+
+		si := grpc.ServiceInfo{}
+		for _, method := range methods {
+			si.Methods = append(si.Methods, grpc.MethodInfo{
+				Name: method[strings.LastIndex(method, "/")+1:],
+			})
+		}
+		info[svc] = si
+	}
+
+	return info
+}
+
+func (r *reflectionResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.registry().Files.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r *reflectionResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.registry().Files.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
+
+func (r *reflectionResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if xt, err := r.registry().Types.FindExtensionByName(field); err == nil {
+		return xt, nil
+	}
+
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r *reflectionResolver) FindExtensionByNumber(message protoreflect.FullName,
+	field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if xt, err := r.registry().Types.FindExtensionByNumber(message, field); err == nil {
+		return xt, nil
+	}
+
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+func (r *reflectionResolver) RangeExtensionsByMessage(message protoreflect.FullName,
+	f func(protoreflect.ExtensionType) bool) {
+	stopped := false
+	r.registry().Types.RangeExtensionsByMessage(message, func(xt protoreflect.ExtensionType) bool {
+		stopped = !f(xt)
+		return !stopped
+	})
+	if !stopped {
+		protoregistry.GlobalTypes.RangeExtensionsByMessage(message, f)
+	}
+}
+
+func (r *reflectionResolver) registry() *dialmanager.Registry {
+	return r.manager.Registry(context.Background())
+}
@@ -0,0 +1,211 @@
+package record
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+)
+
+const (
+	casePrefix      = "recorded-"
+	binHeaderSuffix = "-bin"
+)
+
+var (
+	ErrBidi = errors.New("bidi streaming calls cannot be recorded")
+
+	// requestMarshaler gives the json form rules query, responseMarshaler the one of case bodies.
+	requestMarshaler  = &jsonpb.Marshaler{OrigName: true, EnumsAsInts: true}
+	responseMarshaler = &jsonpb.Marshaler{OrigName: true}
+)
+
+// buildCase builds the case replaying the call: its rule checks the request, its body, stream or
+// status are the ones the upstream answered, along with the headers and trailers it sent.
+func buildCase(desc parser.MethodDesc, call Call) (types.Case, error) {
+	if desc.ClientStreaming && desc.ServerStreaming {
+		return types.Case{}, ErrBidi
+	}
+
+	rule, err := requestRule(desc.In.RawDesc, call.Reqs, desc.ClientStreaming)
+	if err != nil {
+		return types.Case{}, err
+	}
+
+	sum := sha1.Sum([]byte(rule))
+	_case := types.Case{
+		MethodName: call.FullMethodName,
+		Name:       casePrefix + hex.EncodeToString(sum[:6]),
+		Rule:       rule,
+		Headers:    headers(call.Header),
+		Trailers:   headers(call.Trailer),
+	}
+	if call.Err != nil {
+		st := status.Convert(call.Err)
+		_case.Status = types.CaseStatus{
+			Code:    int(st.Code()),
+			Message: st.Message(),
+		}
+	}
+
+	for _, raw := range call.Resps {
+		body, err := messageJSON(desc.Out.RawDesc, raw, responseMarshaler)
+		if err != nil {
+			return types.Case{}, err
+		}
+
+		if !desc.ServerStreaming {
+			_case.Body = body
+			break
+		}
+		_case.Stream = append(_case.Stream, types.StreamMessage{Body: body})
+	}
+
+	return _case, nil
+}
+
+// requestRule builds a rule that holds for requests setting the same fields to the same values as
+// the recorded ones, fields left to their default value are not checked. The messages of client
+// streaming calls are all checked, in order.
+func requestRule(md *desc.MessageDescriptor, reqs [][]byte, clientStreaming bool) (string, error) {
+	var conds []string
+	if clientStreaming {
+		conds = append(conds, fmt.Sprintf("len(messages) == %d", len(reqs)))
+	}
+
+	for i, raw := range reqs {
+		js, err := messageJSON(md, raw, requestMarshaler)
+		if err != nil {
+			return "", err
+		}
+
+		if clientStreaming {
+			conds = append(conds, conditions(gjson.Parse(js), nil, messagesExpr(i))...)
+			continue
+		}
+
+		conds = append(conds, conditions(gjson.Parse(js), nil, jsonExpr)...)
+		break
+	}
+
+	if len(conds) == 0 {
+		return "true", nil
+	}
+
+	return strings.Join(conds, " && "), nil
+}
+
+// conditions compares every leaf of the json value v at path, and the length of every array, to
+// the recorded value. expr gives the expression reading the value at a path, or its length.
+func conditions(v gjson.Result, path []interface{}, expr func(path []interface{}, length bool) string) []string {
+	var conds []string
+	switch {
+	case v.IsObject():
+		v.ForEach(func(key, value gjson.Result) bool {
+			conds = append(conds, conditions(value, append(path[:len(path):len(path)], key.Str), expr)...)
+			return true
+		})
+	case v.IsArray():
+		elems := v.Array()
+		conds = append(conds, fmt.Sprintf("%s == %d", expr(path, true), len(elems)))
+		for i, elem := range elems {
+			conds = append(conds, conditions(elem, append(path[:len(path):len(path)], i), expr)...)
+		}
+	case v.Type == gjson.String:
+		conds = append(conds, fmt.Sprintf("%s == %s", expr(path, false), strconv.Quote(v.Str)))
+	case v.Type == gjson.Number, v.Type == gjson.True, v.Type == gjson.False:
+		conds = append(conds, fmt.Sprintf("%s == %s", expr(path, false), v.Raw))
+	}
+
+	return conds
+}
+
+// jsonExpr reads the path of the request with json, e.g. json("items.0.name").
+func jsonExpr(path []interface{}, length bool) string {
+	segs := make([]string, 0, len(path)+1)
+	for _, seg := range path {
+		switch seg := seg.(type) {
+		case string:
+			segs = append(segs, gjson.Escape(seg))
+		case int:
+			segs = append(segs, strconv.Itoa(seg))
+		}
+	}
+	if length {
+		segs = append(segs, "#")
+	}
+
+	return fmt.Sprintf("json(%s)", strconv.Quote(strings.Join(segs, ".")))
+}
+
+// messagesExpr reads the path of the i-th received message, e.g. messages[1]["items"][0]["name"].
+func messagesExpr(i int) func(path []interface{}, length bool) string {
+	return func(path []interface{}, length bool) string {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "messages[%d]", i)
+		for _, seg := range path {
+			switch seg := seg.(type) {
+			case string:
+				fmt.Fprintf(&sb, "[%s]", strconv.Quote(seg))
+			case int:
+				fmt.Fprintf(&sb, "[%d]", seg)
+			}
+		}
+		if length {
+			return fmt.Sprintf("len(%s)", sb.String())
+		}
+
+		return sb.String()
+	}
+}
+
+func messageJSON(md *desc.MessageDescriptor, raw []byte, marshaler *jsonpb.Marshaler) (string, error) {
+	msg := dynamic.NewMessage(md)
+	if err := msg.Unmarshal(raw); err != nil {
+		return "", err
+	}
+
+	js, err := msg.MarshalJSONPB(marshaler)
+	if err != nil {
+		return "", err
+	}
+
+	return string(js), nil
+}
+
+// headers keeps the metadata a case can send, the values of binary keys are base64 encoded as in
+// case definitions.
+func headers(md metadata.MD) map[string]string {
+	kvs := make(map[string]string)
+	for key, values := range md {
+		if len(values) == 0 || key == "content-type" || strings.HasPrefix(key, ":") ||
+			strings.HasPrefix(key, "grpc-") {
+			continue
+		}
+
+		value := values[0]
+		if strings.HasSuffix(key, binHeaderSuffix) {
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		kvs[key] = value
+	}
+
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	return kvs
+}
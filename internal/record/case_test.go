@@ -0,0 +1,251 @@
+package record
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager/parser"
+	"github.com/zeromicro/grpc-mock/internal/match/rule"
+)
+
+const testProto = `syntax = "proto3";
+package test;
+
+enum Kind {
+  KIND_UNKNOWN = 0;
+  KIND_A = 1;
+}
+
+message Item {
+  string name = 1;
+  repeated string labels = 2;
+}
+
+message Req {
+  string id = 1;
+  int32 n = 2;
+  int64 big = 3;
+  bool flag = 4;
+  Kind kind = 5;
+  repeated Item items = 6;
+  map<string, string> attrs = 7;
+}
+
+message Resp {
+  string id = 1;
+}
+
+service Echo {
+  rpc Unary(Req) returns (Resp);
+  rpc Upload(stream Req) returns (Resp);
+  rpc Watch(Req) returns (stream Resp);
+  rpc Chat(stream Req) returns (stream Resp);
+}
+`
+
+// matchMarshaler gives the json form of requests rules are evaluated on.
+var matchMarshaler = &jsonpb.Marshaler{OrigName: true, EnumsAsInts: true, EmitDefaults: true}
+
+func testMethods(t *testing.T) map[string]parser.MethodDesc {
+	svcs, err := parser.ParseProtos(map[string]string{"test.proto": testProto}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, svcs, 1)
+
+	methods := make(map[string]parser.MethodDesc)
+	for _, method := range svcs[0].Methods {
+		methods[method.Name] = method
+	}
+
+	return methods
+}
+
+func testRaw(t *testing.T, desc parser.MethodDesc, js string) []byte {
+	msg := dynamic.NewMessage(desc.In.RawDesc)
+	require.NoError(t, msg.UnmarshalJSON([]byte(js)))
+	raw, err := msg.Marshal()
+	require.NoError(t, err)
+
+	return raw
+}
+
+// testEnv is the environment a rule is evaluated in for a call carrying the given requests.
+func testEnv(t *testing.T, desc parser.MethodDesc, reqs []string) map[string]interface{} {
+	var first []byte
+	var messages []interface{}
+	for i, req := range reqs {
+		msg := dynamic.NewMessage(desc.In.RawDesc)
+		require.NoError(t, msg.UnmarshalJSON([]byte(req)))
+		js, err := msg.MarshalJSONPB(matchMarshaler)
+		require.NoError(t, err)
+
+		if i == 0 {
+			first = js
+		}
+		messages = append(messages, gjson.ParseBytes(js).Value())
+	}
+
+	return rule.Env(func(path string) interface{} {
+		return gjson.GetBytes(first, path).Value()
+	}, messages)
+}
+
+func TestRequestRule(t *testing.T) {
+	methods := testMethods(t)
+
+	tests := []struct {
+		name   string
+		method string
+		reqs   []string
+		want   string
+		holds  [][]string
+		fails  [][]string
+	}{
+		{
+			name:   "empty",
+			method: "Unary",
+			reqs:   []string{`{}`},
+			want:   `true`,
+			holds:  [][]string{{`{}`}, {`{"id":"any"}`}},
+		},
+		{
+			name:   "scalars",
+			method: "Unary",
+			reqs:   []string{`{"id":"a\"b","n":-3,"big":"9007199254740993","flag":true,"kind":"KIND_A"}`},
+			want: `json("id") == "a\"b" && json("n") == -3 && json("big") == "9007199254740993" && ` +
+				`json("flag") == true && json("kind") == 1`,
+			holds: [][]string{
+				{`{"id":"a\"b","n":-3,"big":"9007199254740993","flag":true,"kind":"KIND_A"}`},
+				{`{"id":"a\"b","n":-3,"big":"9007199254740993","flag":true,"kind":1,"items":[{"name":"x"}]}`},
+			},
+			fails: [][]string{
+				{`{"id":"a\"b","n":-3,"big":"9007199254740992","flag":true,"kind":"KIND_A"}`},
+				{`{"id":"a\"b","n":-3,"big":"9007199254740993","kind":"KIND_A"}`},
+			},
+		},
+		{
+			name:   "arrays",
+			method: "Unary",
+			reqs:   []string{`{"items":[{"name":"x","labels":["l1","l2"]},{"name":"y"}]}`},
+			want: `json("items.#") == 2 && json("items.0.name") == "x" && json("items.0.labels.#") == 2 && ` +
+				`json("items.0.labels.0") == "l1" && json("items.0.labels.1") == "l2" && ` +
+				`json("items.1.name") == "y"`,
+			// the fields left to their default value are not checked
+			holds: [][]string{{`{"items":[{"name":"x","labels":["l1","l2"]},{"name":"y","labels":["l3"]}]}`}},
+			fails: [][]string{
+				{`{"items":[{"name":"x","labels":["l1","l2"]},{"name":"y"},{"name":"z"}]}`},
+				{`{"items":[{"name":"x","labels":["l1"]},{"name":"y"}]}`},
+			},
+		},
+		{
+			name:   "escaped keys",
+			method: "Unary",
+			reqs:   []string{`{"attrs":{"a.b":"1","c*":"2","#":"3"}}`},
+			want:   `json("attrs.\\#") == "3" && json("attrs.a\\.b") == "1" && json("attrs.c\\*") == "2"`,
+			holds:  [][]string{{`{"attrs":{"a.b":"1","c*":"2","#":"3"}}`}},
+			fails:  [][]string{{`{"attrs":{"a.b":"1","cd":"2","#":"3"}}`}, {`{"attrs":{"a.b":"1","c*":"2"}}`}},
+		},
+		{
+			name:   "client streaming",
+			method: "Upload",
+			reqs:   []string{`{"id":"a","items":[{"name":"x"}]}`, `{}`, `{"n":2,"attrs":{"k.1":"v"}}`},
+			want: `len(messages) == 3 && messages[0]["id"] == "a" && len(messages[0]["items"]) == 1 && ` +
+				`messages[0]["items"][0]["name"] == "x" && messages[2]["n"] == 2 && messages[2]["attrs"]["k.1"] == "v"`,
+			holds: [][]string{
+				{`{"id":"a","items":[{"name":"x"}]}`, `{"id":"other"}`, `{"n":2,"attrs":{"k.1":"v"}}`},
+			},
+			fails: [][]string{
+				{`{"id":"a","items":[{"name":"x"}]}`, `{}`},
+				{`{"id":"a","items":[{"name":"x"}]}`, `{}`, `{"n":2,"attrs":{"k.1":"v"}}`, `{}`},
+				{`{"id":"a","items":[{"name":"x"}]}`, `{}`, `{"n":3,"attrs":{"k.1":"v"}}`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desc := methods[test.method]
+			var raws [][]byte
+			for _, req := range test.reqs {
+				raws = append(raws, testRaw(t, desc, req))
+			}
+
+			got, err := requestRule(desc.In.RawDesc, raws, desc.ClientStreaming)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+
+			_, err = rule.Compile(got)
+			require.NoError(t, err)
+
+			for _, reqs := range test.holds {
+				assert.True(t, rule.Holds(got, testEnv(t, desc, reqs)), "%v", reqs)
+			}
+			for _, reqs := range test.fails {
+				assert.False(t, rule.Holds(got, testEnv(t, desc, reqs)), "%v", reqs)
+			}
+		})
+	}
+}
+
+func TestBuildCase(t *testing.T) {
+	methods := testMethods(t)
+
+	header := metadata.Pairs(
+		"x-id", "1",
+		"x-trace-bin", "\x00\x01",
+		"content-type", "application/grpc",
+		"grpc-encoding", "gzip",
+		":authority", "localhost",
+	)
+	trailer := metadata.Pairs("x-done", "yes", "grpc-status", "0")
+
+	unary := methods["Unary"]
+	_case, err := buildCase(unary, Call{
+		FullMethodName: "/test.Echo/Unary",
+		Reqs:           [][]byte{testRaw(t, unary, `{"id":"a"}`)},
+		Resps:          [][]byte{testRaw(t, unary, `{"id":"r"}`)},
+		Header:         header,
+		Trailer:        trailer,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/test.Echo/Unary", _case.MethodName)
+	assert.Regexp(t, `^recorded-[0-9a-f]{12}$`, _case.Name)
+	assert.Equal(t, `json("id") == "a"`, _case.Rule)
+	assert.Equal(t, `{"id":"r"}`, _case.Body)
+	assert.Empty(t, _case.Stream)
+	assert.Equal(t, map[string]string{"x-id": "1", "x-trace-bin": "AAE="}, _case.Headers)
+	assert.Equal(t, map[string]string{"x-done": "yes"}, _case.Trailers)
+	assert.Equal(t, types.CaseStatus{}, _case.Status)
+
+	// the name depends on the rule only, the same request is recorded once
+	again, err := buildCase(unary, Call{
+		FullMethodName: "/test.Echo/Unary",
+		Reqs:           [][]byte{testRaw(t, unary, `{"id":"a"}`)},
+		Err:            status.Error(codes.NotFound, "gone"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, _case.Name, again.Name)
+	assert.Equal(t, types.CaseStatus{Code: int(codes.NotFound), Message: "gone"}, again.Status)
+	assert.Empty(t, again.Headers)
+
+	watch := methods["Watch"]
+	_case, err = buildCase(watch, Call{
+		FullMethodName: "/test.Echo/Watch",
+		Reqs:           [][]byte{testRaw(t, watch, `{"id":"a"}`)},
+		Resps:          [][]byte{testRaw(t, watch, `{"id":"r1"}`), testRaw(t, watch, `{"id":"r2"}`)},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, _case.Body)
+	assert.Equal(t, []types.StreamMessage{{Body: `{"id":"r1"}`}, {Body: `{"id":"r2"}`}}, _case.Stream)
+
+	_, err = buildCase(methods["Chat"], Call{FullMethodName: "/test.Echo/Chat"})
+	assert.ErrorIs(t, err, ErrBidi)
+}
@@ -0,0 +1,6 @@
+package config
+
+type RecordConfig struct {
+	Methods   []string `json:",optional"` // full names of the methods to record, e.g. /helloworld.Greeter/SayHello
+	Upstreams []string `json:",optional"` // names of the upstreams whose methods are all recorded
+}
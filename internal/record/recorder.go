@@ -0,0 +1,142 @@
+package record
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zeromicro/grpc-mock/internal/casemanager"
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/record/config"
+)
+
+// Call is a forwarded call with the raw request and response messages it carried.
+type Call struct {
+	FullMethodName string
+	Reqs           [][]byte
+	Resps          [][]byte
+	Header         metadata.MD
+	Trailer        metadata.MD
+	Err            error // status the upstream ended the call with, nil if it succeeded
+}
+
+// Recorder turns the calls forwarded to the selected methods and upstreams into cases, so that a
+// session with the real upstreams can be replayed without them.
+type Recorder struct {
+	mutex     sync.RWMutex
+	methods   map[string]struct{}
+	upstreams map[string]struct{}
+	recorded  int
+
+	dialManager *dialmanager.Manager
+	caseManager *casemanager.Manager
+}
+
+func NewRecorder(c config.RecordConfig, dialManager *dialmanager.Manager, caseManager *casemanager.Manager) *Recorder {
+	r := &Recorder{
+		methods:     make(map[string]struct{}),
+		upstreams:   make(map[string]struct{}),
+		dialManager: dialManager,
+		caseManager: caseManager,
+	}
+	r.Start(context.Background(), c.Methods, c.Upstreams)
+
+	return r
+}
+
+// Start records the given methods and every method of the given upstreams, on top of the ones
+// already recorded.
+func (r *Recorder) Start(ctx context.Context, methods, upstreams []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, method := range methods {
+		r.methods[method] = struct{}{}
+	}
+	for _, upstream := range upstreams {
+		r.upstreams[upstream] = struct{}{}
+	}
+}
+
+// Stop stops recording the given methods and upstreams, everything if none is given.
+func (r *Recorder) Stop(ctx context.Context, methods, upstreams []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(methods) == 0 && len(upstreams) == 0 {
+		r.methods = make(map[string]struct{})
+		r.upstreams = make(map[string]struct{})
+		return
+	}
+
+	for _, method := range methods {
+		delete(r.methods, method)
+	}
+	for _, upstream := range upstreams {
+		delete(r.upstreams, upstream)
+	}
+}
+
+// Status returns the recorded methods and upstreams, and how many calls were recorded so far.
+func (r *Recorder) Status(ctx context.Context) (methods, upstreams []string, recorded int) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for method := range r.methods {
+		methods = append(methods, method)
+	}
+	for upstream := range r.upstreams {
+		upstreams = append(upstreams, upstream)
+	}
+	sort.Strings(methods)
+	sort.Strings(upstreams)
+
+	return methods, upstreams, r.recorded
+}
+
+// Recording reports whether the calls of the method are recorded.
+func (r *Recorder) Recording(ctx context.Context, method string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if _, ok := r.methods[method]; ok {
+		return true
+	}
+	if len(r.upstreams) == 0 {
+		return false
+	}
+
+	upstream, err := r.dialManager.MethodUpstream(ctx, method)
+	if err != nil {
+		return false
+	}
+	_, ok := r.upstreams[upstream]
+
+	return ok
+}
+
+// Record stores the call as a case whose rule matches requests equal to the recorded one, a
+// later call with the same request replaces it.
+func (r *Recorder) Record(ctx context.Context, call Call) error {
+	desc, err := r.dialManager.MethodDetail(ctx, call.FullMethodName)
+	if err != nil {
+		return err
+	}
+
+	_case, err := buildCase(desc, call)
+	if err != nil {
+		return err
+	}
+
+	if err = r.caseManager.CaseAdd(ctx, _case); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.recorded++
+	r.mutex.Unlock()
+
+	return nil
+}
@@ -1 +1,124 @@
 package storage
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/zeromicro/grpc-mock/internal/controlapi/types"
+	"github.com/zeromicro/grpc-mock/internal/storage/config"
+)
+
+const caseFileName = "cases.json"
+
+// CaseStorage persists the mock cases so that they survive restarts.
+type CaseStorage interface {
+	Load(ctx context.Context) ([]types.Case, error)
+	Save(ctx context.Context, _case types.Case) error
+	Delete(ctx context.Context, methodName, name string) error
+}
+
+func NewCaseStorage(c config.StorageConfig) (CaseStorage, error) {
+	switch c.Type {
+	case config.TypeFile:
+		return newFileCaseStorage(filepath.Join(c.Path, caseFileName))
+	default:
+		return nopCaseStorage{}, nil
+	}
+}
+
+type nopCaseStorage struct{}
+
+func (nopCaseStorage) Load(ctx context.Context) ([]types.Case, error) {
+	return nil, nil
+}
+
+func (nopCaseStorage) Save(ctx context.Context, _case types.Case) error {
+	return nil
+}
+
+func (nopCaseStorage) Delete(ctx context.Context, methodName, name string) error {
+	return nil
+}
+
+type fileCaseStorage struct {
+	mutex sync.Mutex
+	path  string
+	cases map[string]map[string]types.Case // methodName -> caseName -> case
+}
+
+func newFileCaseStorage(path string) (*fileCaseStorage, error) {
+	var cases []types.Case
+	if err := readJSONFile(path, &cases); err != nil {
+		return nil, err
+	}
+
+	s := &fileCaseStorage{
+		path:  path,
+		cases: make(map[string]map[string]types.Case),
+	}
+	for _, _case := range cases {
+		s.put(_case)
+	}
+
+	return s, nil
+}
+
+func (s *fileCaseStorage) Load(ctx context.Context) ([]types.Case, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.list(), nil
+}
+
+func (s *fileCaseStorage) Save(ctx context.Context, _case types.Case) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.put(_case)
+	return writeJSONFile(s.path, s.list())
+}
+
+func (s *fileCaseStorage) Delete(ctx context.Context, methodName, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.cases[methodName][name]; !ok {
+		return nil
+	}
+
+	delete(s.cases[methodName], name)
+	if len(s.cases[methodName]) == 0 {
+		delete(s.cases, methodName)
+	}
+
+	return writeJSONFile(s.path, s.list())
+}
+
+func (s *fileCaseStorage) put(_case types.Case) {
+	if _, ok := s.cases[_case.MethodName]; !ok {
+		s.cases[_case.MethodName] = make(map[string]types.Case)
+	}
+
+	s.cases[_case.MethodName][_case.Name] = _case
+}
+
+// list returns the cases ordered by method and name, which keeps the file diff friendly.
+func (s *fileCaseStorage) list() []types.Case {
+	cases := make([]types.Case, 0, len(s.cases))
+	for _, methodCases := range s.cases {
+		for _, _case := range methodCases {
+			cases = append(cases, _case)
+		}
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		if cases[i].MethodName != cases[j].MethodName {
+			return cases[i].MethodName < cases[j].MethodName
+		}
+		return cases[i].Name < cases[j].Name
+	})
+
+	return cases
+}
@@ -0,0 +1,11 @@
+package config
+
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+type StorageConfig struct {
+	Type string `json:",default=memory,options=memory|file"`
+	Path string `json:",default=data"`
+}
@@ -1 +1,106 @@
 package storage
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/storage/config"
+)
+
+const upstreamFileName = "upstreams.json"
+
+// UpstreamStorage persists the registered upstreams so that they can be re-dialed on startup.
+type UpstreamStorage interface {
+	Load(ctx context.Context) ([]dialmanager.RpcClientConf, error)
+	Save(ctx context.Context, upstream dialmanager.RpcClientConf) error
+	Delete(ctx context.Context, name string) error
+}
+
+func NewUpstreamStorage(c config.StorageConfig) (UpstreamStorage, error) {
+	switch c.Type {
+	case config.TypeFile:
+		return newFileUpstreamStorage(filepath.Join(c.Path, upstreamFileName))
+	default:
+		return nopUpstreamStorage{}, nil
+	}
+}
+
+type nopUpstreamStorage struct{}
+
+func (nopUpstreamStorage) Load(ctx context.Context) ([]dialmanager.RpcClientConf, error) {
+	return nil, nil
+}
+
+func (nopUpstreamStorage) Save(ctx context.Context, upstream dialmanager.RpcClientConf) error {
+	return nil
+}
+
+func (nopUpstreamStorage) Delete(ctx context.Context, name string) error {
+	return nil
+}
+
+type fileUpstreamStorage struct {
+	mutex     sync.Mutex
+	path      string
+	upstreams map[string]dialmanager.RpcClientConf // name -> upstream
+}
+
+func newFileUpstreamStorage(path string) (*fileUpstreamStorage, error) {
+	var upstreams []dialmanager.RpcClientConf
+	if err := readJSONFile(path, &upstreams); err != nil {
+		return nil, err
+	}
+
+	s := &fileUpstreamStorage{
+		path:      path,
+		upstreams: make(map[string]dialmanager.RpcClientConf),
+	}
+	for _, upstream := range upstreams {
+		s.upstreams[upstream.Name] = upstream
+	}
+
+	return s, nil
+}
+
+func (s *fileUpstreamStorage) Load(ctx context.Context) ([]dialmanager.RpcClientConf, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.list(), nil
+}
+
+func (s *fileUpstreamStorage) Save(ctx context.Context, upstream dialmanager.RpcClientConf) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.upstreams[upstream.Name] = upstream
+	return writeJSONFile(s.path, s.list())
+}
+
+func (s *fileUpstreamStorage) Delete(ctx context.Context, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.upstreams[name]; !ok {
+		return nil
+	}
+
+	delete(s.upstreams, name)
+	return writeJSONFile(s.path, s.list())
+}
+
+func (s *fileUpstreamStorage) list() []dialmanager.RpcClientConf {
+	upstreams := make([]dialmanager.RpcClientConf, 0, len(s.upstreams))
+	for _, upstream := range s.upstreams {
+		upstreams = append(upstreams, upstream)
+	}
+
+	sort.Slice(upstreams, func(i, j int) bool {
+		return upstreams[i].Name < upstreams[j].Name
+	})
+
+	return upstreams
+}
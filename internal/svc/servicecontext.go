@@ -1,21 +1,55 @@
 package svc
 
 import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
 	"github.com/zeromicro/grpc-mock/config"
 	"github.com/zeromicro/grpc-mock/internal/casemanager"
 	"github.com/zeromicro/grpc-mock/internal/dialmanager"
+	"github.com/zeromicro/grpc-mock/internal/journal"
+	"github.com/zeromicro/grpc-mock/internal/record"
+	"github.com/zeromicro/grpc-mock/internal/storage"
 )
 
 type ServiceContext struct {
-	Config      config.Config
-	DialManager *dialmanager.Manager
-	CaseManager *casemanager.Manager
+	Config          config.Config
+	DialManager     *dialmanager.Manager
+	CaseManager     *casemanager.Manager
+	Recorder        *record.Recorder
+	Journal         *journal.Journal
+	UpstreamStorage storage.UpstreamStorage
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	ctx := context.Background()
+
+	dialManager := dialmanager.NewManager()
+
+	caseStorage, err := storage.NewCaseStorage(c.Storage)
+	logx.Must(err)
+
+	caseManager := casemanager.NewManager(caseStorage, dialManager)
+	logx.Must(caseManager.Load(ctx))
+
+	upstreamStorage, err := storage.NewUpstreamStorage(c.Storage)
+	logx.Must(err)
+
+	upstreams, err := upstreamStorage.Load(ctx)
+	logx.Must(err)
+
+	dialManager.AddUpstreamWithRetry(ctx, upstreams)
+
+	callJournal, err := journal.NewJournal(c.Journal, dialManager)
+	logx.Must(err)
+
 	return &ServiceContext{
-		Config:      c,
-		DialManager: dialmanager.NewManager(),
-		CaseManager: casemanager.NewManager(),
+		Config:          c,
+		DialManager:     dialManager,
+		CaseManager:     caseManager,
+		Recorder:        record.NewRecorder(c.Record, dialManager, caseManager),
+		Journal:         callJournal,
+		UpstreamStorage: upstreamStorage,
 	}
 }